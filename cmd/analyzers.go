@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/pkg/errors"
+)
+
+// customAnalyzers are the analyzers the service runs after the built-in ones.
+// service.Analyzer is internal to this module, so additional analyzers are
+// added here, e.g. with service.NewAnalyzer, and store their report with
+// report.SetSection.
+var customAnalyzers []service.Analyzer
+
+// registerAnalyzers adds the custom analyzers to the parser.
+func registerAnalyzers(parser *service.ParserService) error {
+	for _, analyzer := range customAnalyzers {
+		if err := parser.Register(analyzer); err != nil {
+			return errors.Wrapf(err, "can't register analyzer %s", analyzer.Name())
+		}
+	}
+	return nil
+}
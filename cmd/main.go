@@ -47,8 +47,45 @@ func main() {
 	}
 
 	staff := service.NewStaffService()
-	fetcher := service.NewFetcherService(httpClient)
-	parser := service.NewParserService(fetcher, cf.WorkerCount)
+	var fetcher service.Fetcher = service.NewFetcherService(httpClient, service.FetcherConfig{
+		HostConcurrency: cf.HostConcurrency,
+		HostDelay:       cf.HostDelay,
+		MaxRetryAfter:   cf.MaxRetryAfter,
+	})
+	if cf.CacheSize > 0 {
+		fetcher = service.NewCachedFetcher(fetcher, service.CacheConfig{
+			PositiveTTL: cf.CacheTTL,
+			NegativeTTL: cf.CacheNegativeTTL,
+			Size:        cf.CacheSize,
+		})
+	}
+	keywords, err := config.LoadKeywords(cf.KeywordsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	keywords.Watch()
+	suppressions, err := config.LoadSuppressions(cf.SuppressionsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	scoring, err := config.LoadScoring(cf.ScoringFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	parser := service.NewParserService(fetcher, service.ParserConfig{
+		WorkerCount:      cf.WorkerCount,
+		LinkCheckTimeout: cf.LinkCheckTimeout,
+		LoginThreshold:   cf.LoginThreshold,
+		Keywords:         keywords,
+		MaxImageSize:     cf.MaxImageSize,
+		IssueDocs:        cf.IssueDocsURL,
+		Suppressions:     suppressions,
+		Scoring:          scoring,
+		FetchAlternates:  cf.FetchAlternates,
+	})
+	if err := registerAnalyzers(parser); err != nil {
+		log.Fatal(err)
+	}
 
 	handler := api.NewHandler(staff, parser)
 	srv := &http.Server{Addr: cf.ApiListener, Handler: handler}
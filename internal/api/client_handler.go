@@ -1,10 +1,11 @@
 package api
 
 import (
-	"context"
+	"fmt"
 	"html/template"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
 	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
@@ -32,8 +33,18 @@ func (h *ClientHandler) Result(w http.ResponseWriter, r *http.Request) *rye.Resp
 	if len(url) == 0 {
 		return BadRequestResponse(nil, "empty url")
 	}
-	ctx := context.Background()
-	response, _ := h.parser.Parse(ctx, url)
+	ctx := r.Context()
+	if r.FormValue("nocache") != "" {
+		ctx = service.WithoutCache(ctx)
+	}
+	response, err := h.parser.Parse(ctx, url)
+	if err != nil {
+		body, status := logAnalyzeError(err, url)
+		tmpl := template.Must(template.ParseFiles("../static/report.html"))
+		w.WriteHeader(status)
+		tmpl.Execute(w, model.ReportBody{Error: body})
+		return nil
+	}
 
 	internalInaccessible := 0
 	for _, link := range response.InternalLinks {
@@ -48,23 +59,163 @@ func (h *ClientHandler) Result(w http.ResponseWriter, r *http.Request) *rye.Resp
 		}
 	}
 
+	// Sections of analyzers which didn't run are nil and left out of the
+	// report.
 	report := make(map[string]string)
-	report["HtmlVersion"] = response.Version
+	if response.Version != nil {
+		report["HtmlVersion"] = fmt.Sprintf("%s (%s mode)", response.Version.Name, response.Version.Mode)
+	}
 	report["Title"] = response.Title
+	if response.Metadata != nil {
+		report["MetaIssues"] = strconv.Itoa(len(response.Metadata.Issues))
+	}
+	if response.StructuredData != nil {
+		report["Entities"] = strconv.Itoa(len(response.StructuredData.Entities))
+		report["EntityIssues"] = strconv.Itoa(len(response.StructuredData.Issues))
+	}
 	report["H1"] = strconv.Itoa(len(response.ListH1))
 	report["H2"] = strconv.Itoa(len(response.ListH2))
 	report["H3"] = strconv.Itoa(len(response.ListH3))
 	report["H4"] = strconv.Itoa(len(response.ListH4))
 	report["H5"] = strconv.Itoa(len(response.ListH5))
 	report["H6"] = strconv.Itoa(len(response.ListH6))
+	if response.Outline != nil {
+		report["OutlineIssues"] = strconv.Itoa(len(response.Outline.Issues))
+	}
 	report["Internal"] = strconv.Itoa(len(response.InternalLinks))
 	report["InternalInaccessible"] = strconv.Itoa(internalInaccessible)
 	report["External"] = strconv.Itoa(len(response.ExternalLinks))
 	report["ExternalInaccessible"] = strconv.Itoa(externalInaccessible)
+	if response.Images != nil {
+		report["Images"] = strconv.Itoa(len(response.Images.Images))
+		report["ImageIssues"] = imageIssues(response.Images.Issues)
+	}
+	var thirdParty, mixedContent, unreachable int
+	for _, resource := range response.Resources {
+		if resource.ThirdParty {
+			thirdParty++
+		}
+		if resource.MixedContent != "" {
+			mixedContent++
+		}
+		if !resource.Accessible {
+			unreachable++
+		}
+	}
+	report["Resources"] = strconv.Itoa(len(response.Resources))
+	report["ThirdPartyResources"] = strconv.Itoa(thirdParty)
+	report["MixedContent"] = strconv.Itoa(mixedContent)
+	report["UnreachableResources"] = strconv.Itoa(unreachable)
+	report["Accessibility"] = accessibilityRules(response.Accessibility)
+	if response.I18n != nil {
+		report["Alternates"] = strconv.Itoa(len(response.I18n.Alternates))
+		report["I18nIssues"] = i18nIssues(response.I18n.Issues)
+	}
+	if response.IssueSummary != nil {
+		report["Issues"] = issueSummary(response.IssueSummary)
+	}
+	report["Score"] = categoryScores(response.Score)
 	report["Login"] = strconv.FormatBool(response.Login)
+	if response.LoginDetection != nil {
+		report["LoginScore"] = strconv.FormatFloat(response.LoginDetection.Score, 'f', 2, 64)
+	}
+	report["Forms"] = formKinds(response.Forms)
+	report["LoginSecurity"] = securityIssues(response.LoginSecurity)
 
+	body := model.ReportBody{Model: report, Score: response.Score}
+	if response.Metadata != nil {
+		body.Card = response.Metadata.Card
+	}
 	tmpl := template.Must(template.ParseFiles("../static/report.html"))
-	tmpl.Execute(w, model.ReportBody{Model: report})
+	tmpl.Execute(w, body)
 
 	return nil
 }
+
+// formKinds counts the forms of each kind, e.g. "login: 1, search: 2".
+func formKinds(forms []*model.Form) string {
+	kinds := make([]string, 0, len(forms))
+	for _, form := range forms {
+		kinds = append(kinds, form.Kind)
+	}
+	return countValues(kinds)
+}
+
+// securityIssues lists the issues with their severity, e.g. "high: insecure-page".
+func securityIssues(issues []*model.SecurityIssue) string {
+	summary := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		summary = append(summary, fmt.Sprintf("%s: %s", issue.Severity, issue.Code))
+	}
+	return strings.Join(summary, ", ")
+}
+
+// imageIssues counts the image issues of each kind, e.g. "missing-alt: 2".
+func imageIssues(issues []*model.ImageIssue) string {
+	codes := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		codes = append(codes, issue.Code)
+	}
+	return countValues(codes)
+}
+
+// accessibilityRules counts the findings of each rule, e.g. "image-alt: 3".
+func accessibilityRules(findings []*model.AccessibilityFinding) string {
+	rules := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		rules = append(rules, finding.Rule)
+	}
+	return countValues(rules)
+}
+
+// issueSummary counts the issues by severity, e.g. "high: 1, medium: 4,
+// low: 2 (3 suppressed)".
+func issueSummary(summary *model.IssueSummary) string {
+	text := fmt.Sprintf("high: %d, medium: %d, low: %d", summary.High, summary.Medium, summary.Low)
+	if summary.Suppressed > 0 {
+		text = fmt.Sprintf("%s (%d suppressed)", text, summary.Suppressed)
+	}
+	return text
+}
+
+// categoryScores shows the overall score and those of the categories, e.g.
+// "72 (content: 80, links: 100, metadata: 33, technical: 75)", or "unrated"
+// without a score.
+func categoryScores(score *model.Score) string {
+	if score == nil {
+		return "unrated"
+	}
+	categories := make([]string, 0, len(score.Categories))
+	for _, category := range score.Categories {
+		categories = append(categories, fmt.Sprintf("%s: %d", category.Name, category.Score))
+	}
+	return fmt.Sprintf("%d (%s)", score.Overall, strings.Join(categories, ", "))
+}
+
+// i18nIssues counts the internationalisation issues of each kind, e.g.
+// "hreflang-invalid: 2".
+func i18nIssues(issues []*model.I18nIssue) string {
+	codes := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		codes = append(codes, issue.Code)
+	}
+	return countValues(codes)
+}
+
+// countValues counts the occurrences of each value in the order they first
+// appear.
+func countValues(values []string) string {
+	var distinct []string
+	counts := make(map[string]int)
+	for _, value := range values {
+		if counts[value] == 0 {
+			distinct = append(distinct, value)
+		}
+		counts[value]++
+	}
+	summary := make([]string, 0, len(distinct))
+	for _, value := range distinct {
+		summary = append(summary, fmt.Sprintf("%s: %d", value, counts[value]))
+	}
+	return strings.Join(summary, ", ")
+}
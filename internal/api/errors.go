@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/log"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/InVisionApp/rye"
+	"github.com/pkg/errors"
+)
+
+// Stable error codes returned to API clients.
+const (
+	CodeInvalidURL         = "INVALID_URL"
+	CodeDNSFailure         = "DNS_FAILURE"
+	CodeConnectionRefused  = "CONNECTION_REFUSED"
+	CodeTimeout            = "UPSTREAM_TIMEOUT"
+	CodeTLSError           = "TLS_ERROR"
+	CodeNotHTML            = "NOT_HTML"
+	CodeUpstream4xx        = "UPSTREAM_4XX"
+	CodeUpstream5xx        = "UPSTREAM_5XX"
+	CodeUnknownAnalyzer    = "UNKNOWN_ANALYZER"
+	CodeInvalidSuppression = "INVALID_SUPPRESSION"
+	CodeInternal           = "INTERNAL_ERROR"
+)
+
+type errorMapping struct {
+	kind   error
+	code   string
+	status int
+}
+
+var fetchErrorMappings = []errorMapping{
+	{kind: service.ErrInvalidURL, code: CodeInvalidURL, status: http.StatusBadRequest},
+	{kind: service.ErrDNS, code: CodeDNSFailure, status: http.StatusBadGateway},
+	{kind: service.ErrConnectionRefused, code: CodeConnectionRefused, status: http.StatusBadGateway},
+	{kind: service.ErrTimeout, code: CodeTimeout, status: http.StatusGatewayTimeout},
+	{kind: service.ErrTLS, code: CodeTLSError, status: http.StatusBadGateway},
+	{kind: service.ErrNotHTML, code: CodeNotHTML, status: http.StatusUnprocessableEntity},
+	{kind: service.ErrUpstreamClient, code: CodeUpstream4xx, status: http.StatusBadGateway},
+	{kind: service.ErrUpstreamServer, code: CodeUpstream5xx, status: http.StatusBadGateway},
+	{kind: service.ErrUnknownAnalyzer, code: CodeUnknownAnalyzer, status: http.StatusBadRequest},
+}
+
+// analyzeError converts a parsing failure to the response body and HTTP status
+// reported to the client.
+func analyzeError(err error, url string) (*model.ErrorResponse, int) {
+	body := &model.ErrorResponse{
+		Code:    CodeInternal,
+		Message: err.Error(),
+		URL:     url,
+	}
+	var fetchErr *service.FetchError
+	if errors.As(err, &fetchErr) {
+		body.UpstreamStatus = fetchErr.StatusCode
+	}
+	for _, m := range fetchErrorMappings {
+		if errors.Is(err, m.kind) {
+			body.Code = m.code
+			return body, m.status
+		}
+	}
+	return body, http.StatusInternalServerError
+}
+
+// logAnalyzeError converts a parsing failure like analyzeError and logs it.
+// Failures of the service are errors, those of the request or the upstream
+// page, 4xx, 502 and 504, only warnings.
+func logAnalyzeError(err error, url string) (*model.ErrorResponse, int) {
+	body, status := analyzeError(err, url)
+	upstream := status == http.StatusBadGateway || status == http.StatusGatewayTimeout
+	if status >= http.StatusInternalServerError && !upstream {
+		log.Errorln(errors.Wrap(err, "can't analyze page"))
+	} else {
+		log.Warnf("can't analyze page: %s", err)
+	}
+	return body, status
+}
+
+func respondWithAnalyzeError(w http.ResponseWriter, err error, url string) *rye.Response {
+	body, status := logAnalyzeError(err, url)
+	return respondWithJson(w, status, body)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
@@ -19,10 +20,35 @@ func NewParserHandler(parseService *service.ParserService) *ParserHandler {
 func (h *ParserHandler) Parse(w http.ResponseWriter, r *http.Request) *rye.Response {
 	ctx := r.Context()
 	request := ctx.Value(ContextUrlPayload).(*model.ParserRequest)
-	resp, err := h.service.Parse(r.Context(), request.URL)
+	if request.NoCache {
+		ctx = service.WithoutCache(ctx)
+	}
+	if len(request.Analyzers) != 0 {
+		ctx = service.WithAnalyzers(ctx, request.Analyzers)
+	}
+	if len(request.Suppressions) != 0 {
+		for i, suppression := range request.Suppressions {
+			if suppression == nil || (suppression.Code == "" && suppression.URL == "") {
+				return respondWithJson(w, http.StatusBadRequest, &model.ErrorResponse{
+					Code:    CodeInvalidSuppression,
+					Message: fmt.Sprintf("suppression %d has neither code nor url", i+1),
+					URL:     request.URL,
+				})
+			}
+		}
+		ctx = service.WithSuppressions(ctx, request.Suppressions)
+	}
+	if request.FetchAlternates {
+		ctx = service.WithAlternates(ctx)
+	}
+	resp, err := h.service.Parse(ctx, request.URL)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return ServerErrorResponse(err, "can't execute service health")
+		return respondWithAnalyzeError(w, err, request.URL)
 	}
 	return respondWithJson(w, http.StatusOK, resp)
 }
+
+// Analyzers lists the analyzers a request can select.
+func (h *ParserHandler) Analyzers(w http.ResponseWriter, r *http.Request) *rye.Response {
+	return respondWithJson(w, http.StatusOK, h.service.Analyzers())
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 )
 
 var _ = Describe("Parsing Test", func() {
@@ -66,15 +67,25 @@ var _ = Describe("Parsing Test", func() {
 	JustBeforeEach(func() {
 		staff = service.NewStaffService()
 		fetcher = &servicefakes.FakeFetcher{}
-		parser = service.NewParserService(fetcher, 1)
+		parser = service.NewParserService(fetcher, service.ParserConfig{WorkerCount: 1})
 
 		router = api.NewHandler(staff, parser)
 	})
 	Describe("should parse html page and compare results", func() {
 		JustBeforeEach(func() {
 			doc, _ := goquery.NewDocumentFromReader(bytes.NewBuffer(htmlPage))
-			doc.Url = &url.URL{Host: "www.w3schools.com"}
-			fetcher.FetchReturns(doc, nil)
+			doc.Url, _ = url.Parse(req.URL)
+			fetcher.FetchReturns(&model.FetchResult{
+				Document: doc,
+				Body:     htmlPage,
+				Meta: &model.FetchMeta{
+					URL:         req.URL,
+					FinalURL:    req.URL,
+					StatusCode:  http.StatusOK,
+					ContentType: "text/html; charset=UTF-8",
+					BodySize:    int64(len(htmlPage)),
+				},
+			}, nil)
 
 			fetcher.IsAccessibleReturnsOnCall(0, &model.WorkerWrapper{Index: 0, Result: true}, nil)
 			fetcher.IsAccessibleReturnsOnCall(1, &model.WorkerWrapper{Index: 1, Result: true}, nil)
@@ -97,8 +108,15 @@ var _ = Describe("Parsing Test", func() {
 
 			Expect(err).To(BeNil())
 
-			Expect(response.Version).To(BeEquivalentTo("HTML5 and beyond"))
+			Expect(response.Version).To(BeEquivalentTo(&model.HTMLVersion{
+				Name:     "HTML5",
+				Doctype:  "HTML",
+				RootName: "html",
+				Mode:     model.ModeNoQuirks,
+			}))
 			Expect(response.Title).To(BeEquivalentTo("W3Schools Online Web Tutorials"))
+			Expect(response.Metadata.Title).To(BeEquivalentTo("W3Schools Online Web Tutorials"))
+			Expect(response.Metadata.Card.Title).To(BeEquivalentTo("W3Schools Online Web Tutorials"))
 
 			Expect(response.ListH1).To(BeEquivalentTo([]string{"Header Level 1"}))
 			Expect(response.ListH2).To(BeEquivalentTo([]string{"Header Level 2"}))
@@ -108,33 +126,205 @@ var _ = Describe("Parsing Test", func() {
 			Expect(response.ListH6).To(BeEquivalentTo([]string{"Header Level 6"}))
 
 			Expect(response.InternalLinks[0]).To(BeEquivalentTo(&model.Link{
-				Name:       "EXERCISES",
-				Url:        "http://www.w3schools.com/htmltryit.asp?filename=tryhtml_default",
-				Accessible: true,
+				Name:        "EXERCISES",
+				Href:        "/html/tryit.asp?filename=tryhtml_default",
+				Url:         "https://www.w3schools.com/html/tryit.asp?filename=tryhtml_default",
+				Accessible:  true,
+				Occurrences: 1,
+				Texts:       []string{"EXERCISES"},
+				Positions:   []string{"html > body > div:nth-child(7) > a:nth-child(1)"},
 			}))
 			Expect(response.InternalLinks[1]).To(BeEquivalentTo(&model.Link{
-				Name:       "CERTIFICATES",
-				Url:        "http://www.w3schools.com/certdefault.asp",
-				Accessible: true,
+				Name:        "CERTIFICATES",
+				Href:        "/cert/default.asp",
+				Url:         "https://www.w3schools.com/cert/default.asp",
+				Accessible:  true,
+				Occurrences: 1,
+				Texts:       []string{"CERTIFICATES"},
+				Positions:   []string{"a#cert_navbtn"},
 			}))
 
 			Expect(response.ExternalLinks[0]).To(BeEquivalentTo(&model.Link{
-				Name:       "LinkedIn",
-				Url:        "https://www.linkedin.com/company/w3schools.com/",
-				Accessible: true,
+				Name:        "LinkedIn",
+				Href:        "https://www.linkedin.com/company/w3schools.com/",
+				Url:         "https://www.linkedin.com/company/w3schools.com/",
+				Accessible:  true,
+				Occurrences: 1,
+				Texts:       []string{"LinkedIn"},
+				Positions:   []string{"html > body > div:nth-child(7) > div:nth-child(3) > a:nth-child(1)"},
 			}))
 			Expect(response.ExternalLinks[1]).To(BeEquivalentTo(&model.Link{
-				Name:       "Instagram",
-				Url:        "https://www.instagram.com/w3schools.com_official/",
-				Accessible: true,
+				Name:        "Instagram",
+				Href:        "https://www.instagram.com/w3schools.com_official/",
+				Url:         "https://www.instagram.com/w3schools.com_official/",
+				Accessible:  true,
+				Occurrences: 1,
+				Texts:       []string{"Instagram"},
+				Positions:   []string{"html > body > div:nth-child(7) > div:nth-child(3) > a:nth-child(2)"},
 			}))
 			Expect(response.ExternalLinks[2]).To(BeEquivalentTo(&model.Link{
-				Name:       "Facebook",
-				Url:        "https://www.facebook.com/w3schoolscom/",
-				Accessible: true,
+				Name:        "Facebook",
+				Href:        "https://www.facebook.com/w3schoolscom/",
+				Url:         "https://www.facebook.com/w3schoolscom/",
+				Accessible:  true,
+				Occurrences: 1,
+				Texts:       []string{"Facebook"},
+				Positions:   []string{"html > body > div:nth-child(7) > div:nth-child(3) > a:nth-child(3)"},
 			}))
 
 			Expect(response.Login).To(BeEquivalentTo(true))
+			Expect(response.LoginDetection.Score).To(BeNumerically(">=", response.LoginDetection.Threshold))
+			Expect(response.LoginDetection.Signals).To(ContainElement("submit-text:sign in"))
+			Expect(response.Forms).To(HaveLen(1))
+			Expect(response.Forms[0].Kind).To(BeEquivalentTo(model.FormLogin))
+			Expect(response.Forms[0].Inputs).To(HaveLen(2))
+
+			Expect(response.Fetch).NotTo(BeNil())
+			Expect(response.Fetch.FinalURL).To(BeEquivalentTo(req.URL))
+			Expect(response.Fetch.StatusCode).To(BeEquivalentTo(http.StatusOK))
+			Expect(response.Fetch.ContentType).To(BeEquivalentTo("text/html; charset=UTF-8"))
+			Expect(response.Fetch.BodySize).To(BeEquivalentTo(len(htmlPage)))
+
+			Expect(response.IssueSummary).NotTo(BeNil())
+			summary := response.IssueSummary
+			Expect(summary.High + summary.Medium + summary.Low).To(BeEquivalentTo(len(response.Issues)))
+
+			_, url := fetcher.FetchArgsForCall(0)
+			Expect(url).To(BeEquivalentTo(req.URL))
+		})
+	})
+	Describe("should select the analyzers", func() {
+		It("should list the analyzers", func() {
+			w := httptest.NewRecorder()
+			request, _ := http.NewRequest(http.MethodGet, "/api/v1/parsing/analyzers", nil)
+
+			router.ServeHTTP(w, request)
+
+			var names []string
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			Expect(json.Unmarshal(body, &names)).To(BeNil())
+			Expect(names).To(ContainElement(service.AnalyzerLinks))
+		})
+		It("should apply the suppressions of the request", func() {
+			doc, _ := goquery.NewDocumentFromReader(bytes.NewBuffer(htmlPage))
+			doc.Url, _ = url.Parse(req.URL)
+			fetcher.FetchReturns(&model.FetchResult{Document: doc, Body: htmlPage, Meta: &model.FetchMeta{URL: req.URL}}, nil)
+
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(model.ParserRequest{
+				URL:          req.URL,
+				Analyzers:    []string{service.AnalyzerMetadata},
+				Suppressions: []*model.Suppression{{Code: "description-*"}},
+			})
+			request, _ := http.NewRequest(http.MethodPost, "/api/v1/parsing/page/analyze", bytes.NewBuffer(reqBody))
+
+			router.ServeHTTP(w, request)
+
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			response := model.ParserResponse{}
+			Expect(json.Unmarshal(body, &response)).To(BeNil())
+			Expect(response.Issues).To(HaveLen(1))
+			Expect(response.Issues[0].Code).To(BeEquivalentTo(model.MetaImageMissing))
+			Expect(response.IssueSummary.Suppressed).To(BeEquivalentTo(1))
+		})
+		It("should reject suppressions without code and url", func() {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(model.ParserRequest{URL: req.URL, Suppressions: []*model.Suppression{{}}})
+			request, _ := http.NewRequest(http.MethodPost, "/api/v1/parsing/page/analyze", bytes.NewBuffer(reqBody))
+
+			router.ServeHTTP(w, request)
+
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			response := model.ErrorResponse{}
+			Expect(json.Unmarshal(body, &response)).To(BeNil())
+			Expect(w.Code).To(BeEquivalentTo(http.StatusBadRequest))
+			Expect(response.Code).To(BeEquivalentTo(api.CodeInvalidSuppression))
+			Expect(fetcher.FetchCallCount()).To(BeZero())
+		})
+		It("should reject unknown analyzers", func() {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(model.ParserRequest{URL: req.URL, Analyzers: []string{"spelling"}})
+			request, _ := http.NewRequest(http.MethodPost, "/api/v1/parsing/page/analyze", bytes.NewBuffer(reqBody))
+
+			router.ServeHTTP(w, request)
+
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			response := model.ErrorResponse{}
+			Expect(json.Unmarshal(body, &response)).To(BeNil())
+			Expect(w.Code).To(BeEquivalentTo(http.StatusBadRequest))
+			Expect(response.Code).To(BeEquivalentTo(api.CodeUnknownAnalyzer))
+			Expect(fetcher.FetchCallCount()).To(BeZero())
+		})
+	})
+	Describe("should map fetch failures to error codes", func() {
+		var fetchErr error
+
+		JustBeforeEach(func() {
+			fetcher.FetchReturns(nil, fetchErr)
+		})
+		doRequest := func() (*httptest.ResponseRecorder, model.ErrorResponse) {
+			w := httptest.NewRecorder()
+			reqBody, _ := json.Marshal(req)
+			request, _ := http.NewRequest(http.MethodPost, "/api/v1/parsing/page/analyze", bytes.NewBuffer(reqBody))
+
+			router.ServeHTTP(w, request)
+
+			body, _ := ioutil.ReadAll(w.Result().Body)
+			response := model.ErrorResponse{}
+			Expect(json.Unmarshal(body, &response)).To(BeNil())
+			return w, response
+		}
+		Context("when the upstream page is missing", func() {
+			BeforeEach(func() {
+				fetchErr = &service.FetchError{Kind: service.ErrUpstreamClient, URL: req.URL, StatusCode: http.StatusNotFound}
+			})
+			It("should respond with upstream 4xx code", func() {
+				w, response := doRequest()
+				Expect(w.Code).To(BeEquivalentTo(http.StatusBadGateway))
+				Expect(response.Code).To(BeEquivalentTo(api.CodeUpstream4xx))
+				Expect(response.UpstreamStatus).To(BeEquivalentTo(http.StatusNotFound))
+				Expect(response.URL).To(BeEquivalentTo(req.URL))
+			})
+		})
+		Context("when the url is invalid", func() {
+			BeforeEach(func() {
+				fetchErr = &service.FetchError{Kind: service.ErrInvalidURL, URL: req.URL}
+			})
+			It("should respond with bad request", func() {
+				w, response := doRequest()
+				Expect(w.Code).To(BeEquivalentTo(http.StatusBadRequest))
+				Expect(response.Code).To(BeEquivalentTo(api.CodeInvalidURL))
+			})
+		})
+		Context("when the upstream times out", func() {
+			BeforeEach(func() {
+				fetchErr = &service.FetchError{Kind: service.ErrTimeout, URL: req.URL}
+			})
+			It("should respond with gateway timeout", func() {
+				w, response := doRequest()
+				Expect(w.Code).To(BeEquivalentTo(http.StatusGatewayTimeout))
+				Expect(response.Code).To(BeEquivalentTo(api.CodeTimeout))
+			})
+		})
+		Context("when the content is not html", func() {
+			BeforeEach(func() {
+				fetchErr = &service.FetchError{Kind: service.ErrNotHTML, URL: req.URL, StatusCode: http.StatusOK}
+			})
+			It("should respond with unprocessable entity", func() {
+				w, response := doRequest()
+				Expect(w.Code).To(BeEquivalentTo(http.StatusUnprocessableEntity))
+				Expect(response.Code).To(BeEquivalentTo(api.CodeNotHTML))
+			})
+		})
+		Context("when the failure is unknown", func() {
+			BeforeEach(func() {
+				fetchErr = errors.New("boom")
+			})
+			It("should respond with internal error", func() {
+				w, response := doRequest()
+				Expect(w.Code).To(BeEquivalentTo(http.StatusInternalServerError))
+				Expect(response.Code).To(BeEquivalentTo(api.CodeInternal))
+			})
 		})
 	})
 })
@@ -37,6 +37,10 @@ func NewHandler(
 		parserHandler.Parse,
 	})).Methods(http.MethodPost)
 
+	v1.Handle("/parsing/analyzers", middlewareHandler.Handle([]rye.Handler{
+		parserHandler.Analyzers,
+	})).Methods(http.MethodGet)
+
 	//////////////////////////////////////////////////////////////////////////////
 	// Client
 	//////////////////////////////////////////////////////////////////////////////
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/log"
 	v "github.com/go-ozzo/ozzo-validation/v4"
@@ -17,6 +18,35 @@ type Config struct {
 	ApiListener string
 	RunStatus   string
 	WorkerCount int
+	// LinkCheckTimeout bounds the time spent checking the links of one page.
+	LinkCheckTimeout time.Duration
+	// HostConcurrency limits simultaneous requests to one host.
+	HostConcurrency int
+	// HostDelay is the minimum delay between requests to the same host.
+	HostDelay time.Duration
+	// MaxRetryAfter is the longest Retry-After wait honoured before retrying.
+	MaxRetryAfter time.Duration
+	// CacheSize bounds the number of cached link checks, zero disables the cache.
+	CacheSize int
+	// CacheTTL is how long accessible links are cached.
+	CacheTTL time.Duration
+	// CacheNegativeTTL is how long inaccessible links are cached.
+	CacheNegativeTTL time.Duration
+	// LoginThreshold is the score from which a form is a login form.
+	LoginThreshold float64
+	// KeywordsFile holds the login keyword dictionaries.
+	KeywordsFile string
+	// MaxImageSize is the size in bytes from which an image is oversized.
+	MaxImageSize int64
+	// SuppressionsFile holds the issues accepted on every page.
+	SuppressionsFile string
+	// IssueDocsURL is the page documenting the issue codes.
+	IssueDocsURL string
+	// ScoringFile holds the weights and thresholds of the SEO score.
+	ScoringFile string
+	// FetchAlternates allows fetching hreflang alternates to check their
+	// return links.
+	FetchAlternates bool
 }
 
 func (c Config) Validate() error {
@@ -35,6 +65,15 @@ func InitConfig() *Config {
 		logrus.Error(err)
 	}
 	viper.AutomaticEnv()
+	viper.SetDefault("API_HOST_CONCURRENCY", 4)
+	viper.SetDefault("API_HOST_DELAY", 100*time.Millisecond)
+	viper.SetDefault("API_MAX_RETRY_AFTER", 5*time.Second)
+	viper.SetDefault("API_CACHE_SIZE", 10000)
+	viper.SetDefault("API_CACHE_TTL", time.Hour)
+	viper.SetDefault("API_CACHE_NEGATIVE_TTL", 5*time.Minute)
+	viper.SetDefault("API_KEYWORDS_FILE", "../configs/keywords.yaml")
+	viper.SetDefault("API_SUPPRESSIONS_FILE", "../configs/suppressions.yaml")
+	viper.SetDefault("API_SCORING_FILE", "../configs/scoring.yaml")
 	c := new(Config)
 	c.RunStatus = "INIT"
 	c.ServiceName = "web_page_analyzer"
@@ -63,6 +102,20 @@ func InitConfig() *Config {
 	}
 	c.ApiListener = viper.GetString("API_LISTENER")
 	c.WorkerCount = viper.GetInt("API_WORKER_COUNT")
+	c.LinkCheckTimeout = viper.GetDuration("API_LINK_CHECK_TIMEOUT")
+	c.HostConcurrency = viper.GetInt("API_HOST_CONCURRENCY")
+	c.HostDelay = viper.GetDuration("API_HOST_DELAY")
+	c.MaxRetryAfter = viper.GetDuration("API_MAX_RETRY_AFTER")
+	c.CacheSize = viper.GetInt("API_CACHE_SIZE")
+	c.CacheTTL = viper.GetDuration("API_CACHE_TTL")
+	c.CacheNegativeTTL = viper.GetDuration("API_CACHE_NEGATIVE_TTL")
+	c.LoginThreshold = viper.GetFloat64("API_LOGIN_THRESHOLD")
+	c.KeywordsFile = viper.GetString("API_KEYWORDS_FILE")
+	c.MaxImageSize = viper.GetInt64("API_MAX_IMAGE_SIZE")
+	c.SuppressionsFile = viper.GetString("API_SUPPRESSIONS_FILE")
+	c.IssueDocsURL = viper.GetString("API_ISSUE_DOCS_URL")
+	c.ScoringFile = viper.GetString("API_SCORING_FILE")
+	c.FetchAlternates = viper.GetBool("API_FETCH_ALTERNATES")
 	if err := c.Validate(); err != nil {
 		logrus.Error(err)
 		os.Exit(-1)
@@ -0,0 +1,15 @@
+package config_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+func TestConfig(t *testing.T) {
+	logrus.SetLevel(logrus.ErrorLevel)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Config")
+}
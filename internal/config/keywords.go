@@ -0,0 +1,104 @@
+package config
+
+import (
+	"math"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/log"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// KeywordStore serves the login keyword dictionaries of a file and keeps
+// them up to date when the file changes.
+type KeywordStore struct {
+	path       string
+	viper      *viper.Viper
+	dictionary atomic.Value
+}
+
+// LoadKeywords reads the keyword dictionaries from path. A missing file
+// leaves the built-in English dictionary in place.
+func LoadKeywords(path string) (*KeywordStore, error) {
+	s := &KeywordStore{path: path, viper: viper.New()}
+	s.dictionary.Store(model.DefaultKeywords())
+	if path == "" {
+		return s, nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		log.Warnf("keywords file %s not found, using the built-in dictionary", path)
+		s.path = ""
+		return s, nil
+	}
+	s.viper.SetConfigFile(path)
+	if err := s.viper.ReadInConfig(); err != nil {
+		return nil, errors.Wrapf(err, "can't read keywords file %s", path)
+	}
+	if err := s.decode(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Keywords returns the current dictionaries.
+func (s *KeywordStore) Keywords() *model.KeywordDictionary {
+	return s.dictionary.Load().(*model.KeywordDictionary)
+}
+
+// Watch reloads the dictionaries whenever the file changes. A file that
+// fails to load keeps the previous dictionaries in use.
+func (s *KeywordStore) Watch() {
+	if s.path == "" {
+		return
+	}
+	s.viper.OnConfigChange(func(event fsnotify.Event) {
+		if err := s.decode(); err != nil {
+			log.Error(err)
+			return
+		}
+		log.Infof("keywords reloaded from %s", s.path)
+	})
+	s.viper.WatchConfig()
+}
+
+func (s *KeywordStore) decode() error {
+	dictionary := new(model.KeywordDictionary)
+	if err := s.viper.Unmarshal(dictionary); err != nil {
+		return errors.Wrapf(err, "can't decode keywords file %s", s.path)
+	}
+	if err := validateKeywords(dictionary); err != nil {
+		return errors.Wrapf(err, "invalid keywords file %s", s.path)
+	}
+	s.dictionary.Store(dictionary)
+	return nil
+}
+
+func validateKeywords(dictionary *model.KeywordDictionary) error {
+	if len(dictionary.Languages) == 0 {
+		return errors.New("no languages defined")
+	}
+	dictionary.Default = strings.ToLower(dictionary.Default)
+	if _, ok := dictionary.Languages[dictionary.Default]; !ok {
+		return errors.Errorf("default language %q is not defined", dictionary.Default)
+	}
+	for lang, keywords := range dictionary.Languages {
+		if keywords == nil {
+			return errors.Errorf("language %q has no keywords", lang)
+		}
+		for _, list := range [][]model.Keyword{keywords.Keywords, keywords.Submit} {
+			for _, keyword := range list {
+				if strings.TrimSpace(keyword.Term) == "" {
+					return errors.Errorf("language %q has an empty term", lang)
+				}
+				if math.IsNaN(keyword.Weight) || math.IsInf(keyword.Weight, 0) {
+					return errors.Errorf("term %q has an invalid weight", keyword.Term)
+				}
+			}
+		}
+	}
+	return nil
+}
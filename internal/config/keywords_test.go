@@ -0,0 +1,88 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/config"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const germanKeywords = `
+default: de
+languages:
+  de:
+    keywords:
+      - {term: passwort, weight: 0.1}
+    submit:
+      - {term: anmelden, weight: 0.3}
+`
+
+var _ = Describe("Keywords Test", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "keywords")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	It("should load the dictionaries of the file", func() {
+		store, err := config.LoadKeywords(write("keywords.yaml", germanKeywords))
+		Expect(err).NotTo(HaveOccurred())
+
+		keywords := store.Keywords()
+		Expect(keywords.Default).To(Equal("de"))
+		Expect(keywords.ForLanguage("de-CH").Submit[0].Term).To(Equal("anmelden"))
+		Expect(keywords.ForLanguage("de-CH").Submit[0].Weight).To(BeEquivalentTo(0.3))
+	})
+
+	It("should load the bundled dictionaries", func() {
+		store, err := config.LoadKeywords("../../configs/keywords.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Keywords().Languages).To(HaveKey("en"))
+		Expect(store.Keywords().Languages).To(HaveKey("de"))
+	})
+
+	It("should use the built-in dictionary without a file", func() {
+		store, err := config.LoadKeywords(filepath.Join(dir, "missing.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Keywords().Languages).To(HaveKey("en"))
+	})
+
+	It("should reject an invalid file", func() {
+		_, err := config.LoadKeywords(write("keywords.yaml", "default: fr\nlanguages:\n  de: {}\n"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reload the file when it changes", func() {
+		path := write("keywords.yaml", germanKeywords)
+		store, err := config.LoadKeywords(path)
+		Expect(err).NotTo(HaveOccurred())
+		store.Watch()
+
+		write("keywords.yaml", germanKeywords+`
+  en:
+    submit:
+      - {term: sign in, weight: 0.3}
+`)
+		Eventually(func() map[string]*model.LanguageKeywords {
+			return store.Keywords().Languages
+		}, 5*time.Second, 50*time.Millisecond).Should(HaveKey("en"))
+	})
+})
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"math"
+	"os"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/log"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// LoadScoring reads the scoring rules from path. A missing file leaves the
+// built-in rules in place.
+func LoadScoring(path string) (*model.ScoringRules, error) {
+	if path == "" {
+		return model.DefaultScoringRules(), nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		log.Warnf("scoring file %s not found, using the built-in rules", path)
+		return model.DefaultScoringRules(), nil
+	}
+	file := viper.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil {
+		return nil, errors.Wrapf(err, "can't read scoring file %s", path)
+	}
+	rules := new(model.ScoringRules)
+	if err := file.Unmarshal(rules); err != nil {
+		return nil, errors.Wrapf(err, "can't decode scoring file %s", path)
+	}
+	if err := validateScoring(rules); err != nil {
+		return nil, errors.Wrapf(err, "invalid scoring file %s", path)
+	}
+	return rules, nil
+}
+
+func validWeight(weight float64) bool {
+	return weight >= 0 && !math.IsInf(weight, 0) && !math.IsNaN(weight)
+}
+
+func validateScoring(rules *model.ScoringRules) error {
+	if len(rules.Categories) == 0 {
+		return errors.New("no categories defined")
+	}
+	for name, category := range rules.Categories {
+		if category == nil || len(category.Rules) == 0 {
+			return errors.Errorf("category %q has no rules", name)
+		}
+		if !validWeight(category.Weight) {
+			return errors.Errorf("category %q has an invalid weight", name)
+		}
+		for _, rule := range category.Rules {
+			if rule == nil || !containsFact(rule.Fact) {
+				return errors.Errorf("category %q has a rule with an unknown fact", name)
+			}
+			if !validWeight(rule.Weight) || !validWeight(rule.Tolerance) {
+				return errors.Errorf("rule %q has an invalid weight or tolerance", rule.Fact)
+			}
+			if rule.Min != nil && rule.Max != nil && *rule.Min > *rule.Max {
+				return errors.Errorf("rule %q has a minimum above its maximum", rule.Fact)
+			}
+		}
+	}
+	return nil
+}
+
+func containsFact(name string) bool {
+	for _, fact := range model.ScoreFacts {
+		if fact == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/config"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scoring Test", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "scoring")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	write := func(content string) string {
+		path := filepath.Join(dir, "scoring.yaml")
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	It("should load the rules of the file", func() {
+		rules, err := config.LoadScoring(write(`
+categories:
+  content:
+    weight: 2
+    rules:
+      - {fact: title-length, weight: 1.5, min: 10, max: 60, tolerance: 20}
+      - {fact: h1-count, weight: 1, max: 1}
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		content := rules.Categories[model.CategoryContent]
+		Expect(content.Weight).To(Equal(2.0))
+		min, max, one := 10.0, 60.0, 1.0
+		Expect(content.Rules).To(Equal([]*model.ScoreRule{
+			{Fact: model.FactTitleLength, Weight: 1.5, Min: &min, Max: &max, Tolerance: 20},
+			{Fact: model.FactH1Count, Weight: 1, Max: &one},
+		}))
+	})
+
+	It("should load the bundled file with the built-in rules", func() {
+		rules, err := config.LoadScoring("../../configs/scoring.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(Equal(model.DefaultScoringRules()))
+	})
+
+	It("should use the built-in rules without a file", func() {
+		rules, err := config.LoadScoring(filepath.Join(dir, "missing.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(Equal(model.DefaultScoringRules()))
+	})
+
+	It("should reject rules of unknown facts", func() {
+		_, err := config.LoadScoring(write(`
+categories:
+  content:
+    weight: 1
+    rules:
+      - {fact: word-count, weight: 1, min: 300}
+`))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject bounds in the wrong order", func() {
+		_, err := config.LoadScoring(write(`
+categories:
+  content:
+    weight: 1
+    rules:
+      - {fact: title-length, weight: 1, min: 60, max: 10}
+`))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/log"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// LoadSuppressions reads the issue suppressions from path. A missing file
+// suppresses nothing.
+func LoadSuppressions(path string) ([]*model.Suppression, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		log.Warnf("suppressions file %s not found, no issue is suppressed", path)
+		return nil, nil
+	}
+	file := viper.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil {
+		return nil, errors.Wrapf(err, "can't read suppressions file %s", path)
+	}
+	var suppressions []*model.Suppression
+	if err := file.UnmarshalKey("suppressions", &suppressions); err != nil {
+		return nil, errors.Wrapf(err, "can't decode suppressions file %s", path)
+	}
+	for i, suppression := range suppressions {
+		if suppression == nil || (suppression.Code == "" && suppression.URL == "") {
+			return nil, errors.Errorf("invalid suppressions file %s: suppression %d has neither code nor url", path, i+1)
+		}
+	}
+	return suppressions, nil
+}
@@ -0,0 +1,68 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/config"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Suppressions Test", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "suppressions")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	write := func(content string) string {
+		path := filepath.Join(dir, "suppressions.yaml")
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	It("should load the suppressions of the file", func() {
+		suppressions, err := config.LoadSuppressions(write(`
+suppressions:
+  - code: broken-link
+    url: https://www.linkedin.com/*
+    reason: needs a session
+  - code: og-image-missing
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(suppressions).To(Equal([]*model.Suppression{
+			{Code: "broken-link", URL: "https://www.linkedin.com/*", Reason: "needs a session"},
+			{Code: "og-image-missing"},
+		}))
+	})
+
+	It("should load the bundled file", func() {
+		suppressions, err := config.LoadSuppressions("../../configs/suppressions.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(suppressions).To(BeEmpty())
+	})
+
+	It("should suppress nothing without a file", func() {
+		suppressions, err := config.LoadSuppressions(filepath.Join(dir, "missing.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(suppressions).To(BeEmpty())
+	})
+
+	It("should reject suppressions without a pattern", func() {
+		_, err := config.LoadSuppressions(write(`
+suppressions:
+  - reason: everything
+`))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,28 @@
+package model
+
+// Accessibility rules checked by the audit, the codes of their issues.
+const (
+	A11yHTMLLang         = "html-lang"
+	A11yLabel            = "label"
+	A11yImageAlt         = "image-alt"
+	A11yLinkName         = "link-name"
+	A11yDuplicateID      = "duplicate-id"
+	A11yAriaRole         = "aria-role"
+	A11yAriaAttribute    = "aria-attribute"
+	A11yAriaReference    = "aria-reference"
+	A11yLandmarkMain     = "landmark-main"
+	A11yLandmarkUnique   = "landmark-unique"
+	A11yPositiveTabindex = "tabindex"
+)
+
+// AccessibilityFinding is a failure of a WCAG 2.1 success criterion found in
+// the markup of the page.
+type AccessibilityFinding struct {
+	Rule string `json:"rule"`
+	// Criterion is the number of the success criterion, e.g. "1.1.1",
+	// and Level its conformance level.
+	Criterion string `json:"criterion"`
+	Level     string `json:"level"`
+	Selector  string `json:"selector"`
+	Message   string `json:"message"`
+}
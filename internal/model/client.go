@@ -2,4 +2,7 @@ package model
 
 type ReportBody struct {
 	Model map[string]string
+	Card  *SocialCard
+	Score *Score
+	Error *ErrorResponse
 }
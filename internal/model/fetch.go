@@ -0,0 +1,26 @@
+package model
+
+import "github.com/PuerkitoBio/goquery"
+
+// FetchResult is the analyzed page together with what the server sent back.
+// Body is the raw document, the parsed Document loses details such as where
+// the doctype was written.
+type FetchResult struct {
+	Document *goquery.Document
+	Body     []byte
+	Meta     *FetchMeta
+}
+
+// FetchMeta describes the HTTP exchange behind a fetched document.
+// Truncated is set when the document exceeded the size limit and only its
+// beginning was analyzed.
+type FetchMeta struct {
+	URL         string              `json:"url"`
+	FinalURL    string              `json:"finalUrl"`
+	StatusCode  int                 `json:"statusCode"`
+	Headers     map[string][]string `json:"headers,omitempty"`
+	ContentType string              `json:"contentType"`
+	BodySize    int64               `json:"bodySize"`
+	Truncated   bool                `json:"truncated,omitempty"`
+	Redirects   []string            `json:"redirects,omitempty"`
+}
@@ -0,0 +1,36 @@
+package model
+
+// Kinds of forms told apart by the form classifier.
+const (
+	FormLogin         = "login"
+	FormSignup        = "signup"
+	FormSearch        = "search"
+	FormNewsletter    = "newsletter"
+	FormContact       = "contact"
+	FormCheckout      = "checkout"
+	FormPasswordReset = "password-reset"
+	FormOther         = "other"
+)
+
+// Form describes a <form> of the page.
+type Form struct {
+	// Selector is the CSS selector locating the form.
+	Selector string `json:"selector"`
+	Kind     string `json:"kind"`
+	Method   string `json:"method"`
+	// Action is the absolute url the form submits to.
+	Action     string       `json:"action"`
+	Enctype    string       `json:"enctype"`
+	LoginScore float64      `json:"loginScore"`
+	Inputs     []*FormInput `json:"inputs"`
+}
+
+// FormInput describes an input, select or textarea of a form. Type is
+// "select" or "textarea" for those elements.
+type FormInput struct {
+	Type         string `json:"type"`
+	Name         string `json:"name,omitempty"`
+	ID           string `json:"id,omitempty"`
+	Required     bool   `json:"required"`
+	Autocomplete string `json:"autocomplete,omitempty"`
+}
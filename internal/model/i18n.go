@@ -0,0 +1,55 @@
+package model
+
+// Internationalisation issue codes.
+const (
+	HreflangInvalid         = "hreflang-invalid"
+	HreflangDuplicate       = "hreflang-duplicate"
+	HreflangMissingSelf     = "hreflang-missing-self"
+	HreflangMissingXDefault = "hreflang-missing-x-default"
+	HreflangMissingReturn   = "hreflang-missing-return"
+	LangInvalid             = "lang-invalid"
+	LangMismatch            = "lang-mismatch"
+	DirInvalid              = "dir-invalid"
+	DirMissing              = "dir-missing"
+)
+
+// XDefault is the hreflang of the page shown to users of no listed language.
+const XDefault = "x-default"
+
+// Internationalization describes the language of the page and its
+// alternates in other languages and regions.
+type Internationalization struct {
+	// Lang and Dir are the attributes of the html element, ContentLanguage
+	// the Content-Language header of the response.
+	Lang            string       `json:"lang,omitempty"`
+	Dir             string       `json:"dir,omitempty"`
+	ContentLanguage string       `json:"contentLanguage,omitempty"`
+	Alternates      []*Alternate `json:"alternates"`
+	// ReturnLinksTruncated is set when alternates past the first ones
+	// weren't fetched for their return links.
+	ReturnLinksTruncated bool         `json:"returnLinksTruncated,omitempty"`
+	Issues               []*I18nIssue `json:"issues"`
+}
+
+// Alternate is a link rel="alternate" with a hreflang attribute.
+type Alternate struct {
+	Hreflang string `json:"hreflang"`
+	// Href is the attribute as written, Url the absolute url it resolves to.
+	Href     string `json:"href"`
+	Url      string `json:"url"`
+	Selector string `json:"selector"`
+	// Language is the BCP 47 tag of the alternate, empty for x-default and
+	// invalid tags.
+	Language string `json:"language,omitempty"`
+	// ReturnLink tells whether the alternate page links back to the page,
+	// it is nil when the alternate wasn't fetched.
+	ReturnLink *bool `json:"returnLink,omitempty"`
+}
+
+type I18nIssue struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Selector string `json:"selector,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
@@ -0,0 +1,51 @@
+package model
+
+// Elements and attributes images are collected from.
+const (
+	ImageSourceImg     = "img"
+	ImageSourceSrcset  = "srcset"
+	ImageSourcePicture = "picture"
+)
+
+// Image issue codes.
+const (
+	ImageMissingAlt        = "missing-alt"
+	ImageMissingDimensions = "missing-dimensions"
+	ImageBroken            = "broken-image"
+	ImageOversized         = "oversized-image"
+)
+
+// ImageInventory lists every image candidate of the page: the src of an
+// <img>, each candidate of its srcset and of the <source> elements of a
+// <picture>.
+type ImageInventory struct {
+	Images []*Image      `json:"images"`
+	Issues []*ImageIssue `json:"issues"`
+}
+
+type Image struct {
+	// Source tells where the candidate comes from: the src of an img, a
+	// srcset candidate of an img or a candidate of a picture source.
+	Source string `json:"source"`
+	// Src is the url as written, Url the absolute url it resolves to.
+	Src string `json:"src"`
+	Url string `json:"url"`
+	// Descriptor is the width or density descriptor of a srcset
+	// candidate, e.g. "640w" or "2x".
+	Descriptor string `json:"descriptor,omitempty"`
+	// Alt is the alternative text of the img, nil when it has none.
+	Alt        *string `json:"alt"`
+	Width      string  `json:"width,omitempty"`
+	Height     string  `json:"height,omitempty"`
+	Loading    string  `json:"loading,omitempty"`
+	Selector   string  `json:"selector"`
+	Accessible bool    `json:"accessible"`
+	LinkStatus
+}
+
+type ImageIssue struct {
+	Code     string `json:"code"`
+	Url      string `json:"url,omitempty"`
+	Selector string `json:"selector"`
+	Message  string `json:"message"`
+}
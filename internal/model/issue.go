@@ -0,0 +1,52 @@
+package model
+
+// Severities of issues.
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// Codes of the issues which have no section of their own.
+const (
+	IssueBrokenLink     = "broken-link"
+	IssueBrokenResource = "broken-resource"
+	IssueMixedContent   = "mixed-content"
+	IssueQuirksMode     = "quirks-mode"
+)
+
+// Issue is a finding of any analyzer, in the shape shared by all of them.
+type Issue struct {
+	// Code identifies the finding, it doesn't change between releases.
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	// Analyzer is the name of the analyzer which reported the issue.
+	Analyzer string `json:"analyzer"`
+	Message  string `json:"message"`
+	// Selector locates the element the issue was found on, URL the
+	// resource it concerns.
+	Selector string `json:"selector,omitempty"`
+	URL      string `json:"url,omitempty"`
+	// DocURL links to the description of the code.
+	DocURL string `json:"docUrl,omitempty"`
+}
+
+// IssueSummary counts the reported issues by severity, and the issues
+// hidden by suppressions.
+type IssueSummary struct {
+	High       int `json:"high"`
+	Medium     int `json:"medium"`
+	Low        int `json:"low"`
+	Suppressed int `json:"suppressed"`
+}
+
+// Suppression hides the issues with a code on the pages, or concerning the
+// urls, matching a pattern. Both Code and URL are patterns where * matches
+// any text, an empty pattern matches everything, but a suppression needs
+// at least one of them.
+type Suppression struct {
+	Code string `json:"code,omitempty" mapstructure:"code"`
+	URL  string `json:"url,omitempty" mapstructure:"url"`
+	// Reason tells why the issues are accepted.
+	Reason string `json:"reason,omitempty" mapstructure:"reason"`
+}
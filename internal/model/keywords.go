@@ -0,0 +1,93 @@
+package model
+
+import "strings"
+
+// Keyword is a weighted term of a login keyword dictionary.
+type Keyword struct {
+	Term   string  `json:"term" mapstructure:"term"`
+	Weight float64 `json:"weight" mapstructure:"weight"`
+}
+
+// LanguageKeywords are the login terms of one language. Keywords are
+// looked up in the texts of a form, Submit in the text of its buttons.
+type LanguageKeywords struct {
+	Keywords []Keyword `json:"keywords" mapstructure:"keywords"`
+	Submit   []Keyword `json:"submit" mapstructure:"submit"`
+}
+
+// KeywordDictionary holds the login terms of every language, keyed by the
+// primary language subtag, e.g. "de" for pages with lang="de-AT".
+type KeywordDictionary struct {
+	Default   string                       `json:"default" mapstructure:"default"`
+	Languages map[string]*LanguageKeywords `json:"languages" mapstructure:"languages"`
+}
+
+// DefaultKeywords returns the English dictionary used when no dictionary
+// file is configured.
+func DefaultKeywords() *KeywordDictionary {
+	return &KeywordDictionary{
+		Default: "en",
+		Languages: map[string]*LanguageKeywords{
+			"en": {
+				Keywords: []Keyword{
+					{Term: "login", Weight: 0.1},
+					{Term: "log in", Weight: 0.1},
+					{Term: "pass", Weight: 0.1},
+					{Term: "password", Weight: 0.1},
+					{Term: "name", Weight: 0.05},
+					{Term: "email", Weight: 0.05},
+					{Term: "username", Weight: 0.1},
+					{Term: "sign in", Weight: 0.1},
+					{Term: "sign up", Weight: 0.05},
+				},
+				Submit: []Keyword{
+					{Term: "login", Weight: 0.3},
+					{Term: "log in", Weight: 0.3},
+					{Term: "log on", Weight: 0.3},
+					{Term: "signin", Weight: 0.3},
+					{Term: "sign in", Weight: 0.3},
+				},
+			},
+		},
+	}
+}
+
+// ForLanguage returns the terms for a page in the given language, falling
+// back to the default language when the dictionary has none for it.
+func (d *KeywordDictionary) ForLanguage(lang string) *LanguageKeywords {
+	primary := strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(primary, "-_"); i >= 0 {
+		primary = primary[:i]
+	}
+	if keywords, ok := d.Languages[primary]; ok {
+		return keywords
+	}
+	if keywords, ok := d.Languages[d.Default]; ok {
+		return keywords
+	}
+	return &LanguageKeywords{}
+}
+
+// MatchKeywords returns the keywords found in the text.
+func (l *LanguageKeywords) MatchKeywords(text string) []Keyword {
+	return matchAny(text, l.Keywords)
+}
+
+// MatchSubmit returns the submit phrase found in a button text.
+func (l *LanguageKeywords) MatchSubmit(text string) (Keyword, bool) {
+	if matched := matchAny(text, l.Submit); len(matched) > 0 {
+		return matched[0], true
+	}
+	return Keyword{}, false
+}
+
+func matchAny(text string, keywords []Keyword) []Keyword {
+	var matched []Keyword
+	text = strings.ToLower(text)
+	for _, keyword := range keywords {
+		if strings.Contains(text, strings.ToLower(keyword.Term)) {
+			matched = append(matched, keyword)
+		}
+	}
+	return matched
+}
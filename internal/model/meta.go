@@ -0,0 +1,51 @@
+package model
+
+// Metadata issue codes.
+const (
+	MetaTitleMissing        = "title-missing"
+	MetaTitleTooShort       = "title-too-short"
+	MetaTitleTooLong        = "title-too-long"
+	MetaDescriptionMissing  = "description-missing"
+	MetaDescriptionTooShort = "description-too-short"
+	MetaDescriptionTooLong  = "description-too-long"
+	MetaImageMissing        = "og-image-missing"
+	MetaImageRelative       = "og-image-relative"
+	MetaCanonicalRelative   = "canonical-relative"
+	MetaCanonicalOtherHost  = "canonical-other-host"
+)
+
+// Metadata holds the meta tags of the page. OpenGraph and Twitter map the
+// properties, e.g. "og:title", to the content of their first tag.
+type Metadata struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Robots      string `json:"robots,omitempty"`
+	Viewport    string `json:"viewport,omitempty"`
+	Charset     string `json:"charset,omitempty"`
+	// Canonical is the canonical link as written, CanonicalURL the
+	// absolute url it resolves to.
+	Canonical    string            `json:"canonical,omitempty"`
+	CanonicalURL string            `json:"canonicalUrl,omitempty"`
+	OpenGraph    map[string]string `json:"openGraph"`
+	Twitter      map[string]string `json:"twitter"`
+	// Card is the social card sharing the page shows, built from the
+	// OpenGraph, Twitter and plain meta tags in this order.
+	Card   *SocialCard  `json:"card"`
+	Issues []*MetaIssue `json:"issues"`
+}
+
+// SocialCard is what social networks show for a shared link.
+type SocialCard struct {
+	Type        string `json:"type,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+	URL         string `json:"url,omitempty"`
+	SiteName    string `json:"siteName,omitempty"`
+}
+
+type MetaIssue struct {
+	Code    string `json:"code"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
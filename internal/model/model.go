@@ -2,24 +2,153 @@ package model
 
 type ParserRequest struct {
 	URL string `json:"url"`
+	// NoCache makes the analysis check every link again instead of
+	// using results cached by earlier analyses.
+	NoCache bool `json:"noCache,omitempty"`
+	// Analyzers names the analyzers to run, all of them when empty.
+	Analyzers []string `json:"analyzers,omitempty"`
+	// Suppressions hide accepted issues, in addition to the configured ones.
+	Suppressions []*Suppression `json:"suppressions,omitempty"`
+	// FetchAlternates makes the analysis fetch the hreflang alternates of
+	// the page, even when the service doesn't by default.
+	FetchAlternates bool `json:"fetchAlternates,omitempty"`
 }
 
 type ParserResponse struct {
-	Version       string   `json:"versionHtml"`
-	Title         string   `json:"title"`
-	ListH1        []string `json:"listH1,omitempty"`
-	ListH2        []string `json:"listH2,omitempty"`
-	ListH3        []string `json:"listH3,omitempty"`
-	ListH4        []string `json:"listH4,omitempty"`
-	ListH5        []string `json:"listH5,omitempty"`
-	ListH6        []string `json:"listH6,omitempty"`
-	InternalLinks []*Link  `json:"internalLinks,omitempty"`
-	ExternalLinks []*Link  `json:"externalLinks,omitempty"`
-	Login         bool     `json:"login"`
+	Version        *HTMLVersion            `json:"versionHtml"`
+	Title          string                  `json:"title"`
+	Metadata       *Metadata               `json:"metadata"`
+	StructuredData *StructuredData         `json:"structuredData"`
+	ListH1         []string                `json:"listH1,omitempty"`
+	ListH2         []string                `json:"listH2,omitempty"`
+	ListH3         []string                `json:"listH3,omitempty"`
+	ListH4         []string                `json:"listH4,omitempty"`
+	ListH5         []string                `json:"listH5,omitempty"`
+	ListH6         []string                `json:"listH6,omitempty"`
+	Outline        *Outline                `json:"outline"`
+	InternalLinks  []*Link                 `json:"internalLinks,omitempty"`
+	ExternalLinks  []*Link                 `json:"externalLinks,omitempty"`
+	Images         *ImageInventory         `json:"images"`
+	Resources      []*Resource             `json:"resources"`
+	Accessibility  []*AccessibilityFinding `json:"accessibility"`
+	SpecialLinks   *SpecialLinks           `json:"specialLinks,omitempty"`
+	LinkIssues     []*LinkIssue            `json:"linkIssues,omitempty"`
+	Login          bool                    `json:"login"`
+	LoginSecurity  []*SecurityIssue        `json:"loginSecurity"`
+	LoginDetection *LoginDetection         `json:"loginDetection,omitempty"`
+	Forms          []*Form                 `json:"forms"`
+	I18n           *Internationalization   `json:"i18n"`
+	Fetch          *FetchMeta              `json:"fetch,omitempty"`
+	// Analyzers lists the analyzers which ran, in the order they did.
+	Analyzers []string `json:"analyzers"`
+	Score     *Score   `json:"score"`
+	// Issues lists the findings of all analyzers but the suppressed ones.
+	Issues       []*Issue      `json:"issues"`
+	IssueSummary *IssueSummary `json:"issueSummary"`
+	// Sections holds the reports of analyzers registered outside the
+	// service, by analyzer name.
+	Sections map[string]interface{} `json:"sections,omitempty"`
+}
+
+// SetSection stores the report of the named analyzer in Sections.
+func (r *ParserResponse) SetSection(name string, section interface{}) {
+	if r.Sections == nil {
+		r.Sections = make(map[string]interface{})
+	}
+	r.Sections[name] = section
 }
 
 type Link struct {
-	Name       string `json:"name"`
+	Name string `json:"name"`
+	// Href is the attribute as written in the document, Url is the
+	// absolute url it resolves to.
+	Href       string `json:"href"`
 	Url        string `json:"url"`
 	Accessible bool   `json:"accessible"`
+	// Occurrences is the number of anchors in the document pointing to
+	// the url, Texts the distinct texts they use and Positions the CSS
+	// selectors locating them.
+	Occurrences int      `json:"occurrences"`
+	Texts       []string `json:"texts,omitempty"`
+	Positions   []string `json:"positions,omitempty"`
+	// Valid is set for links whose target can be validated without
+	// requesting it, e.g. the address of a mailto: link.
+	Valid *bool `json:"valid,omitempty"`
+	// FragmentValid is set for links with a fragment into a document the
+	// analyzer has, it tells whether the document has a matching anchor.
+	FragmentValid *bool `json:"fragmentValid,omitempty"`
+	LinkStatus
+}
+
+// SpecialLinks groups the links which aren't http(s) pages and are
+// never checked for accessibility.
+type SpecialLinks struct {
+	Mailto     []*Link `json:"mailto,omitempty"`
+	Tel        []*Link `json:"tel,omitempty"`
+	Ftp        []*Link `json:"ftp,omitempty"`
+	Data       []*Link `json:"data,omitempty"`
+	Fragment   []*Link `json:"fragment,omitempty"`
+	Javascript []*Link `json:"javascript,omitempty"`
+	Other      []*Link `json:"other,omitempty"`
+}
+
+// Link issue codes.
+const (
+	LinkIssueJavascript   = "javascript-link"
+	LinkIssueInvalidEmail = "invalid-email"
+	LinkIssueInvalidPhone = "invalid-phone"
+	LinkIssueFragment     = "broken-fragment"
+)
+
+type LinkIssue struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Href    string `json:"href"`
+	Message string `json:"message"`
+}
+
+// Failure categories of a link check.
+const (
+	FailureTimeout     = "timeout"
+	FailureDNS         = "dns"
+	FailureConnection  = "connection"
+	FailureTLS         = "tls"
+	FailureClientError = "4xx"
+	FailureServerError = "5xx"
+	FailureRateLimited = "rate_limited"
+	FailureInvalidURL  = "invalid"
+	FailureCanceled    = "canceled"
+	FailureOther       = "other"
+)
+
+// LinkStatus is the outcome of checking a single link.
+type LinkStatus struct {
+	Method     string `json:"method,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	FinalURL   string `json:"finalUrl,omitempty"`
+	Redirects  int    `json:"redirects,omitempty"`
+	LatencyMs  int64  `json:"latencyMs"`
+	Failure    string `json:"failure,omitempty"`
+	Cached     bool   `json:"cached"`
+	// ContentLength and ContentType are taken from the response headers,
+	// ContentLength is zero when the server didn't send it.
+	ContentLength int64  `json:"contentLength,omitempty"`
+	ContentType   string `json:"contentType,omitempty"`
+}
+
+// LoginDetection explains the login verdict of the form scoring highest.
+type LoginDetection struct {
+	Score     float64 `json:"score"`
+	Threshold float64 `json:"threshold"`
+	IsLogin   bool    `json:"isLogin"`
+	// Language is the page language the keyword dictionary was chosen by.
+	Language string   `json:"language,omitempty"`
+	Signals  []string `json:"signals"`
+}
+
+type ErrorResponse struct {
+	Code           string `json:"code"`
+	Message        string `json:"message"`
+	URL            string `json:"url,omitempty"`
+	UpstreamStatus int    `json:"upstreamStatus,omitempty"`
 }
@@ -0,0 +1,33 @@
+package model
+
+// Outline issue codes.
+const (
+	HeadingH1Missing    = "h1-missing"
+	HeadingH1Multiple   = "h1-multiple"
+	HeadingSkippedLevel = "skipped-level"
+	HeadingEmpty        = "empty-heading"
+	HeadingHidden       = "hidden-heading"
+)
+
+// Outline is the tree of the headings of the page in document order. A
+// heading holds the lower level headings following it.
+type Outline struct {
+	Headings []*Heading      `json:"headings"`
+	Issues   []*OutlineIssue `json:"issues"`
+}
+
+type Heading struct {
+	Level    int    `json:"level"`
+	Text     string `json:"text"`
+	Selector string `json:"selector"`
+	// Hidden is set for headings hidden from assistive technologies
+	// with aria-hidden.
+	Hidden   bool       `json:"hidden,omitempty"`
+	Children []*Heading `json:"children,omitempty"`
+}
+
+type OutlineIssue struct {
+	Code     string `json:"code"`
+	Selector string `json:"selector,omitempty"`
+	Message  string `json:"message"`
+}
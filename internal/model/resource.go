@@ -0,0 +1,36 @@
+package model
+
+// Kinds of subresources.
+const (
+	ResourceScript     = "script"
+	ResourceStylesheet = "stylesheet"
+	ResourceFont       = "font"
+	ResourceIframe     = "iframe"
+	ResourceVideo      = "video"
+	ResourceAudio      = "audio"
+	ResourcePreload    = "preload"
+)
+
+// Kinds of mixed content. Browsers block active mixed content and may load
+// passive mixed content with a warning.
+const (
+	MixedContentActive  = "active"
+	MixedContentPassive = "passive"
+)
+
+// Resource is a subresource the page loads.
+type Resource struct {
+	Kind string `json:"kind"`
+	// Src is the url as written, Url the absolute url it resolves to.
+	Src string `json:"src"`
+	Url string `json:"url"`
+	// As is the destination of a preload, e.g. "script" or "image".
+	As       string `json:"as,omitempty"`
+	Selector string `json:"selector"`
+	// ThirdParty is set for resources served by another site than the page.
+	ThirdParty bool `json:"thirdParty"`
+	// MixedContent is set for http resources of an https page.
+	MixedContent string `json:"mixedContent,omitempty"`
+	Accessible   bool   `json:"accessible"`
+	LinkStatus
+}
@@ -0,0 +1,153 @@
+package model
+
+import "math"
+
+// Score categories.
+const (
+	CategoryContent   = "content"
+	CategoryMetadata  = "metadata"
+	CategoryLinks     = "links"
+	CategoryTechnical = "technical"
+)
+
+// Facts the scoring rules are evaluated on. Lengths and counts are plain
+// numbers, coverages ratios from 0 to 1, and yes/no facts 1 or 0.
+const (
+	FactTitleLength         = "title-length"
+	FactH1Count             = "h1-count"
+	FactHeadingIssues       = "heading-issues"
+	FactImageAltCoverage    = "image-alt-coverage"
+	FactDescriptionLength   = "description-length"
+	FactCanonical           = "canonical"
+	FactOpenGraphImage      = "og-image"
+	FactBrokenInternalLinks = "broken-internal-links"
+	FactBrokenExternalLinks = "broken-external-links"
+	FactQuirksMode          = "quirks-mode"
+	FactMixedContent        = "mixed-content"
+	FactHreflangIssues      = "hreflang-issues"
+)
+
+// ScoreFacts lists every fact a rule can refer to.
+var ScoreFacts = []string{
+	FactTitleLength,
+	FactH1Count,
+	FactHeadingIssues,
+	FactImageAltCoverage,
+	FactDescriptionLength,
+	FactCanonical,
+	FactOpenGraphImage,
+	FactBrokenInternalLinks,
+	FactBrokenExternalLinks,
+	FactQuirksMode,
+	FactMixedContent,
+	FactHreflangIssues,
+}
+
+// ScoreRule rates a fact. A fact between Min and Max scores fully, outside
+// of them the score falls linearly to nothing over Tolerance, at once when
+// Tolerance is zero. Min and Max are unbounded when nil.
+type ScoreRule struct {
+	Fact      string   `json:"fact" mapstructure:"fact"`
+	Weight    float64  `json:"weight" mapstructure:"weight"`
+	Min       *float64 `json:"min,omitempty" mapstructure:"min"`
+	Max       *float64 `json:"max,omitempty" mapstructure:"max"`
+	Tolerance float64  `json:"tolerance,omitempty" mapstructure:"tolerance"`
+}
+
+// Rate returns the part of the weight the value earns, from 0 to 1.
+func (r *ScoreRule) Rate(value float64) float64 {
+	var distance float64
+	switch {
+	case r.Min != nil && value < *r.Min:
+		distance = *r.Min - value
+	case r.Max != nil && value > *r.Max:
+		distance = value - *r.Max
+	default:
+		return 1
+	}
+	if r.Tolerance <= 0 {
+		return 0
+	}
+	return math.Max(0, 1-distance/r.Tolerance)
+}
+
+// ScoreCategory weighs a category in the overall score and holds its rules.
+type ScoreCategory struct {
+	Weight float64      `json:"weight" mapstructure:"weight"`
+	Rules  []*ScoreRule `json:"rules" mapstructure:"rules"`
+}
+
+// ScoringRules are the categories of the score, by name.
+type ScoringRules struct {
+	Categories map[string]*ScoreCategory `json:"categories" mapstructure:"categories"`
+}
+
+func bound(value float64) *float64 {
+	return &value
+}
+
+// DefaultScoringRules returns the rules used when no rules file is
+// configured.
+func DefaultScoringRules() *ScoringRules {
+	return &ScoringRules{
+		Categories: map[string]*ScoreCategory{
+			CategoryContent: {
+				Weight: 1,
+				Rules: []*ScoreRule{
+					{Fact: FactTitleLength, Weight: 3, Min: bound(10), Max: bound(60), Tolerance: 20},
+					{Fact: FactH1Count, Weight: 2, Min: bound(1), Max: bound(1), Tolerance: 2},
+					{Fact: FactHeadingIssues, Weight: 1, Max: bound(0), Tolerance: 5},
+					{Fact: FactImageAltCoverage, Weight: 2, Min: bound(1), Tolerance: 1},
+				},
+			},
+			CategoryMetadata: {
+				Weight: 1,
+				Rules: []*ScoreRule{
+					{Fact: FactDescriptionLength, Weight: 3, Min: bound(50), Max: bound(160), Tolerance: 50},
+					{Fact: FactCanonical, Weight: 2, Min: bound(1)},
+					{Fact: FactOpenGraphImage, Weight: 1, Min: bound(1)},
+				},
+			},
+			CategoryLinks: {
+				Weight: 1,
+				Rules: []*ScoreRule{
+					{Fact: FactBrokenInternalLinks, Weight: 3, Max: bound(0), Tolerance: 5},
+					{Fact: FactBrokenExternalLinks, Weight: 1, Max: bound(0), Tolerance: 10},
+				},
+			},
+			CategoryTechnical: {
+				Weight: 1,
+				Rules: []*ScoreRule{
+					{Fact: FactQuirksMode, Weight: 2, Max: bound(0)},
+					{Fact: FactMixedContent, Weight: 2, Max: bound(0), Tolerance: 3},
+					{Fact: FactHreflangIssues, Weight: 1, Max: bound(0), Tolerance: 3},
+				},
+			},
+		},
+	}
+}
+
+// Score is the rating of the page, from 0 to 100, overall and by category.
+type Score struct {
+	Overall    int              `json:"overall"`
+	Categories []*CategoryScore `json:"categories"`
+}
+
+// CategoryScore is the score of a category and the rules it's made of.
+// Rules whose fact is unknown, e.g. because its analyzer didn't run, are
+// left out.
+type CategoryScore struct {
+	Name   string       `json:"name"`
+	Score  int          `json:"score"`
+	Weight float64      `json:"weight"`
+	Rules  []*RuleScore `json:"rules"`
+}
+
+// RuleScore is the evaluation of a rule: the fact value and the part of the
+// weight it earned, from 0 to 1.
+type RuleScore struct {
+	Fact   string  `json:"fact"`
+	Value  float64 `json:"value"`
+	Weight float64 `json:"weight"`
+	Rate   float64 `json:"rate"`
+}
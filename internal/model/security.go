@@ -0,0 +1,22 @@
+package model
+
+// Security issue codes of credential forms.
+const (
+	SecurityInsecurePage        = "insecure-page"
+	SecurityInsecureAction      = "insecure-form-action"
+	SecurityCrossOriginAction   = "cross-origin-form-action"
+	SecurityCredentialsInURL    = "credentials-in-url"
+	SecurityAutocompleteOff     = "autocomplete-off"
+	SecurityMissingCSRFToken    = "missing-csrf-token"
+	SecurityCrossOriginPassword = "cross-origin-password-frame"
+)
+
+// SecurityIssue is a weakness of a credential form.
+type SecurityIssue struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// Selector locates the form or frame the issue was found in.
+	Selector string `json:"selector,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
@@ -0,0 +1,47 @@
+package model
+
+// Syntaxes structured data is written in.
+const (
+	SourceJSONLD    = "json-ld"
+	SourceMicrodata = "microdata"
+	SourceRDFa      = "rdfa"
+)
+
+// Structured data issue codes.
+const (
+	StructuredInvalidJSONLD      = "invalid-json-ld"
+	StructuredMissingRequired    = "missing-required-property"
+	StructuredMissingRecommended = "missing-recommended-property"
+)
+
+// StructuredData is the graph of entities the page describes. Nested
+// entities are listed on their own and referenced by their ID.
+type StructuredData struct {
+	Entities []*Entity              `json:"entities"`
+	Issues   []*StructuredDataIssue `json:"issues"`
+}
+
+// Entity is a node of the structured data graph. Types and property names
+// are schema.org terms without the vocabulary prefix, e.g. "Product". ID
+// is the declared identifier or a blank node such as "_:b0".
+type Entity struct {
+	ID         string                      `json:"id"`
+	Types      []string                    `json:"types"`
+	Source     string                      `json:"source"`
+	Properties map[string][]*PropertyValue `json:"properties"`
+}
+
+// PropertyValue is either a literal Value or a Ref to another entity.
+type PropertyValue struct {
+	Value string `json:"value,omitempty"`
+	Ref   string `json:"ref,omitempty"`
+}
+
+type StructuredDataIssue struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Entity   string `json:"entity,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Property string `json:"property,omitempty"`
+	Message  string `json:"message"`
+}
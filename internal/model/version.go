@@ -0,0 +1,22 @@
+package model
+
+// Document modes a browser renders a page in, decided by its doctype.
+const (
+	ModeNoQuirks      = "no-quirks"
+	ModeLimitedQuirks = "limited-quirks"
+	ModeQuirks        = "quirks"
+)
+
+// HTMLVersion describes the doctype of the page and the document mode it
+// puts browsers in.
+type HTMLVersion struct {
+	// Name is the HTML version the doctype declares, "undefined" when it
+	// is missing or unknown.
+	Name string `json:"name"`
+	// Doctype is the doctype as written, without "<!DOCTYPE" and ">".
+	Doctype  string `json:"doctype,omitempty"`
+	RootName string `json:"rootName,omitempty"`
+	PublicID string `json:"publicId,omitempty"`
+	SystemID string `json:"systemId,omitempty"`
+	Mode     string `json:"mode"`
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ariaRoles are the concrete roles of WAI-ARIA 1.2, abstract roles may not
+// be used in content.
+var ariaRoles = stringSet(`alert alertdialog application article banner blockquote button caption cell
+	checkbox code columnheader combobox comment complementary contentinfo definition deletion dialog
+	directory document emphasis feed figure form generic grid gridcell group heading img insertion link
+	list listbox listitem log main mark marquee math menu menubar menuitem menuitemcheckbox menuitemradio
+	meter navigation none note option paragraph presentation progressbar radio radiogroup region row
+	rowgroup rowheader scrollbar search searchbox separator slider spinbutton status strong subscript
+	superscript switch tab table tablist tabpanel term textbox time timer toolbar tooltip tree treegrid
+	treeitem`)
+
+// ariaAttributes are the states and properties of WAI-ARIA 1.2.
+var ariaAttributes = stringSet(`aria-activedescendant aria-atomic aria-autocomplete aria-braillelabel
+	aria-brailleroledescription aria-busy aria-checked aria-colcount aria-colindex aria-colindextext
+	aria-colspan aria-controls aria-current aria-describedby aria-description aria-details aria-disabled
+	aria-dropeffect aria-errormessage aria-expanded aria-flowto aria-grabbed aria-haspopup aria-hidden
+	aria-invalid aria-keyshortcuts aria-label aria-labelledby aria-level aria-live aria-modal
+	aria-multiline aria-multiselectable aria-orientation aria-owns aria-placeholder aria-posinset
+	aria-pressed aria-readonly aria-relevant aria-required aria-roledescription aria-rowcount
+	aria-rowindex aria-rowindextext aria-rowspan aria-selected aria-setsize aria-sort aria-valuemax
+	aria-valuemin aria-valuenow aria-valuetext`)
+
+// ariaBooleans are the attributes taking true or false.
+var ariaBooleans = stringSet(`aria-atomic aria-busy aria-disabled aria-modal aria-multiline
+	aria-multiselectable aria-readonly aria-required`)
+
+// ariaReferences are the attributes referencing other elements by id.
+var ariaReferences = stringSet(`aria-activedescendant aria-controls aria-describedby aria-details
+	aria-errormessage aria-flowto aria-labelledby aria-owns`)
+
+func stringSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+// a11yAudit collects the findings of the accessibility audit of a document.
+type a11yAudit struct {
+	doc *goquery.Document
+	ids idCounts
+	// elements holds the first element of each id, labelled the ids of the
+	// controls with a non-empty label[for].
+	elements map[string]*goquery.Selection
+	labelled map[string]bool
+	findings []*model.AccessibilityFinding
+}
+
+func (a *a11yAudit) report(rule, criterion, level string, s *goquery.Selection, message string) {
+	finding := &model.AccessibilityFinding{
+		Rule:      rule,
+		Criterion: criterion,
+		Level:     level,
+		Message:   message,
+	}
+	if s != nil {
+		finding.Selector = selectorPath(s, a.ids)
+	}
+	a.findings = append(a.findings, finding)
+}
+
+// auditAccessibility checks the document against a static subset of WCAG
+// 2.1: what can be told from the markup alone, without rendering the page.
+func (p *ParserService) auditAccessibility(doc *goquery.Document) []*model.AccessibilityFinding {
+	a := &a11yAudit{
+		doc:      doc,
+		ids:      countIDs(doc),
+		elements: make(map[string]*goquery.Selection),
+		labelled: make(map[string]bool),
+		findings: make([]*model.AccessibilityFinding, 0),
+	}
+	doc.Find("[id]").Each(func(i int, s *goquery.Selection) {
+		if id := s.AttrOr("id", ""); a.elements[id] == nil {
+			a.elements[id] = s
+		}
+	})
+	doc.Find("label[for]").Each(func(i int, label *goquery.Selection) {
+		if normalizeSpace(label.Text()) != "" {
+			a.labelled[label.AttrOr("for", "")] = true
+		}
+	})
+
+	root := doc.Find("html").First()
+	if strings.TrimSpace(root.AttrOr("lang", "")) == "" {
+		a.report(model.A11yHTMLLang, "3.1.1", "A", root, "the html element has no lang attribute")
+	}
+	a.landmarks()
+
+	seen := make(map[string]bool)
+	doc.Find("*").Each(func(i int, s *goquery.Selection) {
+		if id, ok := s.Attr("id"); ok {
+			if seen[id] {
+				a.report(model.A11yDuplicateID, "4.1.1", "A", s, fmt.Sprintf("the id %q is used more than once", id))
+			}
+			seen[id] = true
+		}
+		a.aria(s)
+		if tabindex, err := strconv.Atoi(strings.TrimSpace(s.AttrOr("tabindex", ""))); err == nil && tabindex > 0 {
+			a.report(model.A11yPositiveTabindex, "2.4.3", "A", s,
+				fmt.Sprintf("tabindex=%d changes the focus order of the page", tabindex))
+		}
+
+		switch goquery.NodeName(s) {
+		case "img":
+			if _, ok := s.Attr("alt"); !ok && !decorative(s) && a.labelText(s) == "" {
+				a.report(model.A11yImageAlt, "1.1.1", "A", s, "the image has no text alternative")
+			}
+		case "a":
+			if _, ok := s.Attr("href"); ok && !ariaHidden(s) && a.linkName(s) == "" {
+				a.report(model.A11yLinkName, "2.4.4", "A", s, "the link has no accessible name")
+			}
+		case "input", "select", "textarea":
+			a.control(s)
+		}
+	})
+	return a.findings
+}
+
+// landmarks checks the page has one main landmark, and no more than one
+// banner and contentinfo landmark.
+func (a *a11yAudit) landmarks() {
+	const sectioning = "article, aside, main, nav, section"
+	count := func(selector string, keep func(*goquery.Selection) bool) *goquery.Selection {
+		return a.doc.Find(selector).FilterFunction(func(i int, s *goquery.Selection) bool {
+			return !ariaHidden(s) && (keep == nil || keep(s))
+		})
+	}
+	topLevel := func(tag string) func(*goquery.Selection) bool {
+		return func(s *goquery.Selection) bool {
+			return goquery.NodeName(s) != tag || s.ParentsFiltered(sectioning).Length() == 0
+		}
+	}
+
+	mains := count(`main, [role="main"]`, nil)
+	if mains.Length() == 0 {
+		a.report(model.A11yLandmarkMain, "1.3.1", "A", nil, "the page has no main landmark")
+	}
+	for _, landmark := range []struct {
+		name  string
+		found *goquery.Selection
+	}{
+		{"main", mains},
+		{"banner", count(`header, [role="banner"]`, topLevel("header"))},
+		{"contentinfo", count(`footer, [role="contentinfo"]`, topLevel("footer"))},
+	} {
+		landmark.found.Each(func(i int, s *goquery.Selection) {
+			if i > 0 {
+				a.report(model.A11yLandmarkUnique, "1.3.1", "A", s,
+					fmt.Sprintf("the page has %d %s landmarks", landmark.found.Length(), landmark.name))
+			}
+		})
+	}
+}
+
+// aria checks the role and the aria attributes of the element.
+func (a *a11yAudit) aria(s *goquery.Selection) {
+	if role, ok := s.Attr("role"); ok {
+		for _, token := range strings.Fields(strings.ToLower(role)) {
+			// DPUB-ARIA roles are valid as well
+			if !ariaRoles[token] && !strings.HasPrefix(token, "doc-") {
+				a.report(model.A11yAriaRole, "4.1.2", "A", s, fmt.Sprintf("%q is not a WAI-ARIA role", token))
+			}
+		}
+	}
+	for _, attribute := range s.Nodes[0].Attr {
+		name := strings.ToLower(attribute.Key)
+		if !strings.HasPrefix(name, "aria-") {
+			continue
+		}
+		value := strings.TrimSpace(attribute.Val)
+		switch {
+		case !ariaAttributes[name]:
+			a.report(model.A11yAriaAttribute, "4.1.2", "A", s, fmt.Sprintf("%s is not a WAI-ARIA attribute", name))
+		case ariaBooleans[name] && value != "true" && value != "false":
+			a.report(model.A11yAriaAttribute, "4.1.2", "A", s, fmt.Sprintf("%s must be true or false", name))
+		case ariaReferences[name]:
+			for _, id := range strings.Fields(value) {
+				if a.ids[id] == 0 {
+					a.report(model.A11yAriaReference, "4.1.2", "A", s,
+						fmt.Sprintf("%s references the missing id %q", name, id))
+				}
+			}
+		}
+	}
+}
+
+// control checks a form control has a label.
+func (a *a11yAudit) control(s *goquery.Selection) {
+	fieldType := strings.ToLower(strings.TrimSpace(s.AttrOr("type", "text")))
+	if goquery.NodeName(s) == "input" {
+		switch fieldType {
+		case "hidden":
+			return
+		case "submit", "reset", "button":
+			// buttons are named by their value, or by a default text
+			return
+		case "image":
+			if strings.TrimSpace(s.AttrOr("alt", "")) == "" && a.labelText(s) == "" {
+				a.report(model.A11yImageAlt, "1.1.1", "A", s, "the image button has no text alternative")
+			}
+			return
+		}
+	}
+	if ariaHidden(s) || a.labelText(s) != "" {
+		return
+	}
+	if id := s.AttrOr("id", ""); id != "" && a.labelled[id] {
+		return
+	}
+	if label := s.ParentsFiltered("label").First(); label.Length() > 0 && normalizeSpace(label.Text()) != "" {
+		return
+	}
+	a.report(model.A11yLabel, "4.1.2", "A", s, "the form control has no label")
+}
+
+// labelText returns the name given to the element by aria-label,
+// aria-labelledby or title.
+func (a *a11yAudit) labelText(s *goquery.Selection) string {
+	if label := normalizeSpace(s.AttrOr("aria-label", "")); label != "" {
+		return label
+	}
+	var texts []string
+	for _, id := range strings.Fields(s.AttrOr("aria-labelledby", "")) {
+		if target, ok := a.elements[id]; ok {
+			if text := normalizeSpace(target.Text()); text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+	if len(texts) > 0 {
+		return strings.Join(texts, " ")
+	}
+	return normalizeSpace(s.AttrOr("title", ""))
+}
+
+func (a *a11yAudit) linkName(s *goquery.Selection) string {
+	if name := a.labelText(s); name != "" {
+		return name
+	}
+	return visibleText(s)
+}
+
+// decorative reports whether the image is hidden from assistive technologies
+// on purpose.
+func decorative(s *goquery.Selection) bool {
+	role := strings.ToLower(strings.TrimSpace(s.AttrOr("role", "")))
+	return role == "presentation" || role == "none" || ariaHidden(s)
+}
@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"fmt"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Accessibility audit Test", func() {
+
+	audit := func(page string) []*model.AccessibilityFinding {
+		return parsePage(&servicefakes.FakeFetcher{}, pageURL, page).Accessibility
+	}
+	rules := func(page string) []string {
+		found := []string{}
+		for _, finding := range audit(page) {
+			found = append(found, finding.Rule)
+		}
+		return found
+	}
+
+	It("should only start selectors at unique ids", func() {
+		findings := audit(`<!DOCTYPE html><html lang="en"><body>
+			<main id="content"><div id="x"><img src="a.png"></div><div id="x"><a href="/"></a></div></main>
+			</body></html>`)
+
+		selectors := []string{}
+		for _, finding := range findings {
+			selectors = append(selectors, finding.Selector)
+		}
+		Expect(selectors).To(Equal([]string{
+			"main#content > div:nth-child(1) > img",
+			"main#content > div:nth-child(2)",
+			"main#content > div:nth-child(2) > a",
+		}))
+	})
+
+	It("should report findings with their criterion and selector", func() {
+		findings := audit(`<!DOCTYPE html><html><body>
+			<main><img src="chart.png"><div id="x"></div><div id="x"></div></main>
+			</body></html>`)
+
+		Expect(findings).To(Equal([]*model.AccessibilityFinding{
+			{Rule: model.A11yHTMLLang, Criterion: "3.1.1", Level: "A", Selector: "html",
+				Message: "the html element has no lang attribute"},
+			{Rule: model.A11yImageAlt, Criterion: "1.1.1", Level: "A", Selector: "html > body > main > img:nth-child(1)",
+				Message: "the image has no text alternative"},
+			{Rule: model.A11yDuplicateID, Criterion: "4.1.1", Level: "A", Selector: "html > body > main > div:nth-child(3)",
+				Message: `the id "x" is used more than once`},
+		}))
+	})
+
+	table.DescribeTable("should check the rules",
+		func(body string, expected ...string) {
+			found := rules(fmt.Sprintf(`<!DOCTYPE html><html lang="en"><body><main>%s</main></body></html>`, body))
+			Expect(found).To(Equal(append([]string{}, expected...)))
+		},
+		table.Entry("accessible markup", `
+				<img src="logo.png" alt="Example"><img src="line.png" alt=""><img src="dot.png" role="presentation">
+				<a href="/">Home</a><a href="/search" aria-label="Search"></a><a href="/"><img src="home.png" alt="Home"></a>
+				<label for="email">Email</label><input id="email" type="email">
+				<label>Name <input name="name"></label>
+				<span id="phone-label">Phone</span><input aria-labelledby="phone-label">
+				<input type="hidden" name="token"><input type="submit">
+				<div role="navigation" aria-hidden="false" tabindex="0"></div>
+				<article><header>Post</header><footer>Author</footer></article>`),
+		table.Entry("form controls without labels", `
+				<input name="q" placeholder="Search"><select name="size"></select><textarea></textarea>
+				<input type="image" src="go.png">`,
+			model.A11yLabel, model.A11yLabel, model.A11yLabel, model.A11yImageAlt),
+		table.Entry("links without names", `<a href="/next"></a><a href="/icon"><img src="i.png" alt=""></a><a name="top"></a>`,
+			model.A11yLinkName, model.A11yLinkName),
+		table.Entry("invalid aria", `
+				<div role="buton"></div><div role="doc-chapter"></div><div role="widget"></div>
+				<div aria-labeledby="x"></div><div aria-busy="yes"></div><div aria-describedby="missing"></div>`,
+			model.A11yAriaRole, model.A11yAriaRole, model.A11yAriaAttribute, model.A11yAriaAttribute, model.A11yAriaReference),
+		table.Entry("positive tabindex", `<button tabindex="2">Go</button><span tabindex="-1"></span>`,
+			model.A11yPositiveTabindex),
+	)
+
+	table.DescribeTable("should check the landmark structure",
+		func(body string, expected ...string) {
+			found := rules(fmt.Sprintf(`<!DOCTYPE html><html lang="en"><body>%s</body></html>`, body))
+			Expect(found).To(Equal(append([]string{}, expected...)))
+		},
+		table.Entry("missing main", `<div>content</div>`, model.A11yLandmarkMain),
+		table.Entry("main by role", `<div role="main">content</div>`),
+		table.Entry("two mains", `<main></main><main></main>`, model.A11yLandmarkUnique),
+		table.Entry("two banners", `<header></header><main></main><div role="banner"></div>`, model.A11yLandmarkUnique),
+	)
+})
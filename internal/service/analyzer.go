@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/pkg/errors"
+)
+
+// Names of the built-in analyzers.
+const (
+	AnalyzerVersion        = "version"
+	AnalyzerTitle          = "title"
+	AnalyzerMetadata       = "metadata"
+	AnalyzerStructuredData = "structured-data"
+	AnalyzerHeadings       = "headings"
+	AnalyzerLinks          = "links"
+	AnalyzerImages         = "images"
+	AnalyzerResources      = "resources"
+	AnalyzerAccessibility  = "accessibility"
+	AnalyzerForms          = "forms"
+	AnalyzerLoginSecurity  = "login-security"
+	AnalyzerI18n           = "i18n"
+	// AnalyzerScore rates the page from the sections of the analyzers
+	// run before it.
+	AnalyzerScore = "score"
+)
+
+// ErrUnknownAnalyzer is returned when a request selects an analyzer which
+// isn't registered.
+var ErrUnknownAnalyzer = errors.New("unknown analyzer")
+
+// Analyzer examines a fetched page and adds its section to the report, and
+// its findings to the issues of the report.
+type Analyzer interface {
+	// Name identifies the analyzer in requests selecting the analyzers to run.
+	Name() string
+	Analyze(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error
+}
+
+// AnalyzeFunc is the signature of Analyzer.Analyze.
+type AnalyzeFunc func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error
+
+type funcAnalyzer struct {
+	name    string
+	analyze AnalyzeFunc
+}
+
+// NewAnalyzer returns an analyzer running analyze under the given name.
+func NewAnalyzer(name string, analyze AnalyzeFunc) Analyzer {
+	return &funcAnalyzer{name: name, analyze: analyze}
+}
+
+func (a *funcAnalyzer) Name() string {
+	return a.name
+}
+
+func (a *funcAnalyzer) Analyze(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+	return a.analyze(ctx, result, report)
+}
+
+// AnalyzerRegistry holds the analyzers by name. Analyzers run in the order
+// they were registered, so an analyzer may use the sections of the ones
+// registered before it.
+type AnalyzerRegistry struct {
+	mu        sync.RWMutex
+	analyzers []Analyzer
+	names     map[string]Analyzer
+}
+
+func NewAnalyzerRegistry() *AnalyzerRegistry {
+	return &AnalyzerRegistry{names: make(map[string]Analyzer)}
+}
+
+// Register adds the analyzer, its name must not be taken.
+func (r *AnalyzerRegistry) Register(analyzer Analyzer) error {
+	name := analyzer.Name()
+	if name == "" {
+		return errors.New("analyzer has no name")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.names[name]; ok {
+		return errors.Errorf("analyzer %q is already registered", name)
+	}
+	r.names[name] = analyzer
+	r.analyzers = append(r.analyzers, analyzer)
+	return nil
+}
+
+// Names lists the registered analyzers in the order they run.
+func (r *AnalyzerRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.analyzers))
+	for _, analyzer := range r.analyzers {
+		names = append(names, analyzer.Name())
+	}
+	return names
+}
+
+// Select returns the named analyzers in the order they run, all of them
+// when no name is given.
+func (r *AnalyzerRegistry) Select(names []string) ([]Analyzer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(names) == 0 {
+		return append([]Analyzer(nil), r.analyzers...), nil
+	}
+	for _, name := range names {
+		if _, ok := r.names[name]; !ok {
+			return nil, errors.Wrap(ErrUnknownAnalyzer, name)
+		}
+	}
+	selected := make([]Analyzer, 0, len(names))
+	for _, analyzer := range r.analyzers {
+		if containsString(names, analyzer.Name()) {
+			selected = append(selected, analyzer)
+		}
+	}
+	return selected, nil
+}
+
+type analyzersContextKey struct{}
+
+// WithAnalyzers returns a context whose analyses only run the named
+// analyzers.
+func WithAnalyzers(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, analyzersContextKey{}, names)
+}
+
+func selectedAnalyzers(ctx context.Context) []string {
+	names, _ := ctx.Value(analyzersContextKey{}).([]string)
+	return names
+}
+
+// registerBuiltins registers the analyzers every parser service has.
+func (p *ParserService) registerBuiltins() {
+	builtins := []Analyzer{
+		NewAnalyzer(AnalyzerVersion, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			report.Version = p.version(result)
+			report.Issues = append(report.Issues, p.versionIssues(report.Version)...)
+			return nil
+		}),
+		NewAnalyzer(AnalyzerTitle, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			report.Title = p.title(result.Document)
+			return nil
+		}),
+		NewAnalyzer(AnalyzerMetadata, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			report.Metadata = p.metadata(result.Document)
+			report.Issues = append(report.Issues, p.metaIssues(report.Metadata)...)
+			return nil
+		}),
+		NewAnalyzer(AnalyzerStructuredData, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			report.StructuredData = p.structuredData(result.Document)
+			report.Issues = append(report.Issues, p.structuredDataIssues(report.StructuredData)...)
+			return nil
+		}),
+		NewAnalyzer(AnalyzerHeadings, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			headings := p.headings(result.Document)
+			report.ListH1 = headings.texts[1]
+			report.ListH2 = headings.texts[2]
+			report.ListH3 = headings.texts[3]
+			report.ListH4 = headings.texts[4]
+			report.ListH5 = headings.texts[5]
+			report.ListH6 = headings.texts[6]
+			report.Outline = headings.outline
+			report.Issues = append(report.Issues, p.outlineIssues(headings.outline)...)
+			return nil
+		}),
+		NewAnalyzer(AnalyzerLinks, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			links := p.setInternalLink(ctx, result.Document)
+			report.InternalLinks = links.internal
+			report.ExternalLinks = links.external
+			report.SpecialLinks = links.special
+			report.LinkIssues = links.issues
+			report.Issues = append(report.Issues, p.linkIssues(links)...)
+			return nil
+		}),
+		NewAnalyzer(AnalyzerImages, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			report.Images = p.images(ctx, result.Document)
+			report.Issues = append(report.Issues, p.imageIssues(report.Images)...)
+			return nil
+		}),
+		NewAnalyzer(AnalyzerResources, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			report.Resources = p.resources(ctx, result.Document)
+			report.Issues = append(report.Issues, p.resourceIssues(report.Resources)...)
+			return nil
+		}),
+		NewAnalyzer(AnalyzerAccessibility, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			report.Accessibility = p.auditAccessibility(result.Document)
+			report.Issues = append(report.Issues, p.accessibilityIssues(report.Accessibility)...)
+			return nil
+		}),
+		NewAnalyzer(AnalyzerForms, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			report.Forms, report.LoginDetection = p.inventoryForms(result.Document)
+			report.Login = report.LoginDetection.IsLogin
+			return nil
+		}),
+		NewAnalyzer(AnalyzerLoginSecurity, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			// The audit needs the forms, inventory them when the forms
+			// analyzer isn't selected.
+			forms := report.Forms
+			if forms == nil {
+				forms, _ = p.inventoryForms(result.Document)
+			}
+			report.LoginSecurity = p.auditLogin(ctx, result.Document, forms)
+			report.Issues = append(report.Issues, p.securityIssues(report.LoginSecurity)...)
+			return nil
+		}),
+		NewAnalyzer(AnalyzerI18n, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			report.I18n = p.internationalization(ctx, result)
+			report.Issues = append(report.Issues, p.i18nIssues(report.I18n)...)
+			return nil
+		}),
+		NewAnalyzer(AnalyzerScore, func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			report.Score = p.score(report)
+			return nil
+		}),
+	}
+	for _, analyzer := range builtins {
+		if err := p.analyzers.Register(analyzer); err != nil {
+			panic(err)
+		}
+	}
+}
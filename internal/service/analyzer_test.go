@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("Analyzer registry Test", func() {
+
+	const html = `<!DOCTYPE html><html lang="en"><head><title>Sign in</title></head><body>
+		<h1>Welcome</h1><a href="https://other.com/">other</a>
+		<form action="/login" method="post"><input name="user"><input type="password" name="pass"><button>Log in</button></form>
+		</body></html>`
+
+	var (
+		fetcher *servicefakes.FakeFetcher
+		parser  *service.ParserService
+	)
+	BeforeEach(func() {
+		fetcher = &servicefakes.FakeFetcher{}
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		Expect(err).To(BeNil())
+		doc.Url, _ = url.Parse(pageURL)
+		fetcher.FetchReturns(&model.FetchResult{Document: doc, Meta: &model.FetchMeta{URL: pageURL, FinalURL: pageURL}}, nil)
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			pr.Result = true
+			return pr, nil
+		})
+		parser = service.NewParserService(fetcher, service.ParserConfig{WorkerCount: 2})
+	})
+
+	It("should register the built-in analyzers", func() {
+		Expect(parser.Analyzers()).To(Equal([]string{
+			service.AnalyzerVersion,
+			service.AnalyzerTitle,
+			service.AnalyzerMetadata,
+			service.AnalyzerStructuredData,
+			service.AnalyzerHeadings,
+			service.AnalyzerLinks,
+			service.AnalyzerImages,
+			service.AnalyzerResources,
+			service.AnalyzerAccessibility,
+			service.AnalyzerForms,
+			service.AnalyzerLoginSecurity,
+			service.AnalyzerI18n,
+			service.AnalyzerScore,
+		}))
+	})
+
+	It("should run only the selected analyzers", func() {
+		ctx := service.WithAnalyzers(context.Background(), []string{service.AnalyzerTitle, service.AnalyzerHeadings})
+		response, err := parser.Parse(ctx, pageURL)
+		Expect(err).To(BeNil())
+
+		Expect(response.Title).To(Equal("Sign in"))
+		Expect(response.ListH1).To(Equal([]string{"Welcome"}))
+		Expect(response.Outline).NotTo(BeNil())
+		Expect(response.Version).To(BeNil())
+		Expect(response.ExternalLinks).To(BeEmpty())
+		Expect(response.Forms).To(BeNil())
+		Expect(response.Fetch.URL).To(Equal(pageURL))
+		Expect(response.Analyzers).To(Equal([]string{service.AnalyzerTitle, service.AnalyzerHeadings}))
+		Expect(fetcher.IsAccessibleCallCount()).To(BeZero())
+	})
+
+	It("should audit the login without the forms analyzer", func() {
+		ctx := service.WithAnalyzers(context.Background(), []string{service.AnalyzerLoginSecurity})
+		response, err := parser.Parse(ctx, pageURL)
+		Expect(err).To(BeNil())
+
+		Expect(response.Forms).To(BeNil())
+		var codes []string
+		for _, issue := range response.LoginSecurity {
+			codes = append(codes, issue.Code)
+		}
+		Expect(codes).To(ContainElement(model.SecurityMissingCSRFToken))
+	})
+
+	It("should reject unknown analyzers before fetching the page", func() {
+		ctx := service.WithAnalyzers(context.Background(), []string{service.AnalyzerTitle, "spelling"})
+		_, err := parser.Parse(ctx, pageURL)
+		Expect(errors.Is(err, service.ErrUnknownAnalyzer)).To(BeTrue())
+		Expect(fetcher.FetchCallCount()).To(BeZero())
+	})
+
+	It("should run registered analyzers after the built-in ones", func() {
+		var title string
+		Expect(parser.Register(service.NewAnalyzer("forms-count", func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			title = report.Title
+			report.SetSection("forms-count", result.Document.Find("form").Length())
+			return nil
+		}))).To(Succeed())
+
+		response, err := parser.Parse(context.Background(), pageURL)
+		Expect(err).To(BeNil())
+		Expect(title).To(Equal("Sign in"))
+		Expect(response.Sections).To(Equal(map[string]interface{}{"forms-count": 1}))
+	})
+
+	It("should refuse analyzers without a name or with a taken one", func() {
+		analyze := func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			return nil
+		}
+		Expect(parser.Register(service.NewAnalyzer("", analyze))).NotTo(Succeed())
+		Expect(parser.Register(service.NewAnalyzer(service.AnalyzerLinks, analyze))).NotTo(Succeed())
+	})
+
+	It("should fail the analysis when an analyzer fails", func() {
+		Expect(parser.Register(service.NewAnalyzer("broken", func(ctx context.Context, result *model.FetchResult, report *model.ParserResponse) error {
+			return errors.New("boom")
+		}))).To(Succeed())
+
+		_, err := parser.Parse(context.Background(), pageURL)
+		Expect(err).To(MatchError("analyzer broken: boom"))
+	})
+})
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+)
+
+type cacheContextKey struct{}
+
+// WithoutCache returns a context whose link checks skip cached results.
+// Fresh results are still stored for later requests.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheContextKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheContextKey{}).(bool)
+	return bypass
+}
+
+// CacheConfig holds the lifetime and size of cached link checks.
+type CacheConfig struct {
+	// PositiveTTL is how long accessible links are cached.
+	PositiveTTL time.Duration
+	// NegativeTTL is how long inaccessible links are cached.
+	NegativeTTL time.Duration
+	// Size is the maximum number of cached links.
+	Size int
+}
+
+// CachedFetcher shares link check results between analyses. Links are keyed
+// by their normalized url and the least recently used ones are evicted once
+// the cache is full. Documents are always fetched fresh.
+type CachedFetcher struct {
+	Fetcher
+	config CacheConfig
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	recent  *list.List
+}
+
+type cacheEntry struct {
+	key     string
+	result  bool
+	status  model.LinkStatus
+	expires time.Time
+}
+
+func NewCachedFetcher(fetcher Fetcher, config CacheConfig) *CachedFetcher {
+	return &CachedFetcher{
+		Fetcher: fetcher,
+		config:  config,
+		entries: make(map[string]*list.Element),
+		recent:  list.New(),
+	}
+}
+
+func (c *CachedFetcher) IsAccessible(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+	key := normalizeURL(pr.Url)
+	if !cacheBypassed(ctx) {
+		if entry, ok := c.get(key); ok {
+			pr.Result = entry.result
+			pr.LinkStatus = entry.status
+			pr.Cached = true
+			return pr, nil
+		}
+	}
+	result, err := c.Fetcher.IsAccessible(ctx, pr)
+	if err != nil || result == nil {
+		return result, err
+	}
+	// failures caused by the analysis being canceled or the host throttling
+	// the checks say nothing about the link
+	if ctx.Err() == nil && result.Failure != model.FailureCanceled && result.Failure != model.FailureRateLimited {
+		c.put(key, result)
+	}
+	return result, nil
+}
+
+func (c *CachedFetcher) get(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.recent.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.recent.MoveToFront(element)
+	return entry, true
+}
+
+func (c *CachedFetcher) put(key string, result *model.WorkerWrapper) {
+	ttl := c.config.PositiveTTL
+	if !result.Result {
+		ttl = c.config.NegativeTTL
+	}
+	if ttl <= 0 || c.config.Size <= 0 {
+		return
+	}
+	entry := &cacheEntry{
+		key:     key,
+		result:  result.Result,
+		status:  result.LinkStatus,
+		expires: time.Now().Add(ttl),
+	}
+	entry.status.Cached = false
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.recent.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.recent.PushFront(entry)
+	for c.recent.Len() > c.config.Size {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// normalizeURL returns the cache key of a link: the scheme and host are
+// lower-cased, default ports and fragments dropped and query keys sorted.
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.RawQuery != "" {
+		u.RawQuery = u.Query().Encode()
+	}
+	return u.String()
+}
@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Link cache Test", func() {
+
+	var (
+		fetcher *servicefakes.FakeFetcher
+		config  service.CacheConfig
+		cache   *service.CachedFetcher
+	)
+	BeforeEach(func() {
+		fetcher = &servicefakes.FakeFetcher{}
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			pr.Result = pr.Url != "https://broken.com/"
+			pr.StatusCode = 200
+			return pr, nil
+		})
+		config = service.CacheConfig{PositiveTTL: time.Hour, NegativeTTL: time.Hour, Size: 10}
+	})
+	JustBeforeEach(func() {
+		cache = service.NewCachedFetcher(fetcher, config)
+	})
+	check := func(ctx context.Context, url string) *model.WorkerWrapper {
+		result, err := cache.IsAccessible(ctx, &model.WorkerWrapper{Url: url})
+		Expect(err).To(BeNil())
+		return result
+	}
+
+	It("should answer repeated checks from the cache", func() {
+		Expect(check(context.Background(), "https://example.com/").Cached).To(BeFalse())
+		result := check(context.Background(), "https://example.com/")
+		Expect(result.Cached).To(BeTrue())
+		Expect(result.Result).To(BeTrue())
+		Expect(result.StatusCode).To(BeEquivalentTo(200))
+		Expect(fetcher.IsAccessibleCallCount()).To(BeEquivalentTo(1))
+	})
+
+	It("should key links by their normalized url", func() {
+		check(context.Background(), "HTTPS://Example.com:443?b=2&a=1#top")
+		Expect(check(context.Background(), "https://example.com/?a=1&b=2").Cached).To(BeTrue())
+		Expect(check(context.Background(), "https://example.com/other").Cached).To(BeFalse())
+	})
+
+	It("should check again when the request bypasses the cache", func() {
+		check(context.Background(), "https://example.com/")
+		Expect(check(service.WithoutCache(context.Background()), "https://example.com/").Cached).To(BeFalse())
+		Expect(fetcher.IsAccessibleCallCount()).To(BeEquivalentTo(2))
+	})
+
+	It("should evict the least recently used links", func() {
+		config.Size = 2
+		cache = service.NewCachedFetcher(fetcher, config)
+		check(context.Background(), "https://a.com/")
+		check(context.Background(), "https://b.com/")
+		check(context.Background(), "https://a.com/")
+		check(context.Background(), "https://c.com/")
+		Expect(check(context.Background(), "https://a.com/").Cached).To(BeTrue())
+		Expect(check(context.Background(), "https://b.com/").Cached).To(BeFalse())
+	})
+
+	It("should not cache checks cut short by cancellation", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		check(ctx, "https://example.com/")
+		Expect(check(context.Background(), "https://example.com/").Cached).To(BeFalse())
+	})
+
+	It("should not cache rate limited checks", func() {
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			pr.StatusCode = 429
+			pr.Failure = model.FailureRateLimited
+			return pr, nil
+		})
+		check(context.Background(), "https://example.com/")
+		Expect(check(context.Background(), "https://example.com/").Cached).To(BeFalse())
+		Expect(fetcher.IsAccessibleCallCount()).To(BeEquivalentTo(2))
+	})
+
+	Context("when negative results expire sooner", func() {
+		BeforeEach(func() {
+			config.NegativeTTL = 10 * time.Millisecond
+		})
+		It("should use separate lifetimes", func() {
+			check(context.Background(), "https://example.com/")
+			check(context.Background(), "https://broken.com/")
+			time.Sleep(20 * time.Millisecond)
+			Expect(check(context.Background(), "https://example.com/").Cached).To(BeTrue())
+			Expect(check(context.Background(), "https://broken.com/").Cached).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/log"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/pkg/errors"
+)
+
+// checkLinks checks the accessibility of the links and stores the results on
+// them. Links differing only by their fragment are checked once.
+func (p *ParserService) checkLinks(ctx context.Context, links []*model.Link) {
+	urls := make([]string, 0, len(links))
+	for _, link := range links {
+		urls = append(urls, link.Url)
+	}
+	results := p.checkUnique(ctx, urls)
+	for _, link := range links {
+		result := results(link.Url)
+		link.Accessible = result.Result
+		link.LinkStatus = result.LinkStatus
+	}
+}
+
+// pageChecks are the url checks of one analysis. Its analyzers share the
+// deadline of the checks and the results, so a url found by several of them
+// is checked once.
+type pageChecks struct {
+	// deadline is zero when the checks have no deadline besides the
+	// request context.
+	deadline time.Time
+
+	mu      sync.Mutex
+	results map[string]*model.WorkerWrapper
+}
+
+type checksContextKey struct{}
+
+// withChecks returns a context whose url checks share one deadline,
+// LinkCheckTimeout from now, and their results.
+func (p *ParserService) withChecks(ctx context.Context) context.Context {
+	checks := &pageChecks{results: make(map[string]*model.WorkerWrapper)}
+	if p.config.LinkCheckTimeout > 0 {
+		checks.deadline = time.Now().Add(p.config.LinkCheckTimeout)
+	}
+	return context.WithValue(ctx, checksContextKey{}, checks)
+}
+
+// checkContext returns a context done at the deadline of the checks of the
+// analysis. Contexts outside of an analysis get a deadline of their own.
+func (p *ParserService) checkContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	checks, ok := ctx.Value(checksContextKey{}).(*pageChecks)
+	if !ok {
+		ctx = p.withChecks(ctx)
+		checks = ctx.Value(checksContextKey{}).(*pageChecks)
+	}
+	if checks.deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, checks.deadline)
+}
+
+// checkUnique checks every distinct url once, urls differing only by their
+// fragment being the same, and returns a lookup of the result of each url.
+// Urls the analysis already checked aren't checked again.
+func (p *ParserService) checkUnique(ctx context.Context, urls []string) func(url string) *model.WorkerWrapper {
+	ctx, cancel := p.checkContext(ctx)
+	defer cancel()
+	checks := ctx.Value(checksContextKey{}).(*pageChecks)
+
+	var unique []string
+	pending := make(map[string]bool)
+	checks.mu.Lock()
+	for _, url := range urls {
+		key := normalizeURL(url)
+		if _, ok := checks.results[key]; !ok && !pending[key] {
+			pending[key] = true
+			unique = append(unique, url)
+		}
+	}
+	checks.mu.Unlock()
+
+	results := p.checkURLs(ctx, unique)
+
+	checks.mu.Lock()
+	defer checks.mu.Unlock()
+	for _, result := range results {
+		checks.results[normalizeURL(result.Url)] = result
+	}
+	lookup := make(map[string]*model.WorkerWrapper, len(urls))
+	for _, url := range urls {
+		key := normalizeURL(url)
+		lookup[key] = checks.results[key]
+	}
+	return func(url string) *model.WorkerWrapper {
+		return lookup[normalizeURL(url)]
+	}
+}
+
+// checkURLs checks the urls through the fetcher and returns the results in the
+// order of the urls. The worker pool lives only for the duration of the call,
+// so concurrent analyses never wait on each other. Urls which weren't checked
+// before the context was done are reported as canceled or timed out.
+func (p *ParserService) checkURLs(ctx context.Context, urls []string) []*model.WorkerWrapper {
+	results := make([]*model.WorkerWrapper, len(urls))
+	runPool(ctx, p.config.WorkerCount, len(urls), func(index int) {
+		results[index] = p.checkURL(ctx, index, urls[index])
+	})
+	for index, result := range results {
+		if result == nil {
+			results[index] = &model.WorkerWrapper{
+				Index:      index,
+				Url:        urls[index],
+				LinkStatus: model.LinkStatus{Failure: contextFailure(ctx)},
+			}
+		}
+	}
+	return results
+}
+
+// runPool runs work for the items 0 to n-1 on at most workers goroutines and
+// waits for them. Items not started before the context is done are skipped.
+func runPool(ctx context.Context, workers, n int, work func(index int)) {
+	if workers > n {
+		workers = n
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				work(index)
+			}
+		}()
+	}
+feed:
+	for index := 0; index < n; index++ {
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func (p *ParserService) checkURL(ctx context.Context, index int, url string) *model.WorkerWrapper {
+	pr := &model.WorkerWrapper{Index: index, Url: url}
+	if ctx.Err() != nil {
+		pr.Failure = contextFailure(ctx)
+		return pr
+	}
+	result, err := p.fetcher.IsAccessible(ctx, pr)
+	if err != nil {
+		log.Error(errors.Wrapf(err, "checking %s failed", url))
+		pr.Failure = failureCategory(err)
+		return pr
+	}
+	if result == nil {
+		return pr
+	}
+	// the fetcher may hand back a shared value, keep our own copy
+	checked := *result
+	checked.Index = index
+	checked.Url = url
+	return &checked
+}
+
+// contextFailure returns the failure category of links left unchecked when
+// the context is done.
+func contextFailure(ctx context.Context) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return model.FailureTimeout
+	}
+	return model.FailureCanceled
+}
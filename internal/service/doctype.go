@@ -0,0 +1,266 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"golang.org/x/net/html"
+)
+
+const undefinedVersion = "undefined"
+
+// doctypeVersions names the versions of the known public identifiers.
+var doctypeVersions = map[string]string{
+	"-//w3c//dtd html 4.01//en":                              "HTML 4.01 Strict",
+	"-//w3c//dtd html 4.01 transitional//en":                 "HTML 4.01 Transitional",
+	"-//w3c//dtd html 4.01 frameset//en":                     "HTML 4.01 Frameset",
+	"-//w3c//dtd html 4.0//en":                               "HTML 4.0 Strict",
+	"-//w3c//dtd html 4.0 transitional//en":                  "HTML 4.0 Transitional",
+	"-//w3c//dtd html 4.0 frameset//en":                      "HTML 4.0 Frameset",
+	"-//w3c//dtd html 3.2 final//en":                         "HTML 3.2",
+	"-//w3c//dtd html 3.2//en":                               "HTML 3.2",
+	"-//ietf//dtd html 2.0//en":                              "HTML 2.0",
+	"-//ietf//dtd html//en":                                  "HTML 2.0",
+	"-//w3c//dtd xhtml 1.0 strict//en":                       "XHTML 1.0 Strict",
+	"-//w3c//dtd xhtml 1.0 transitional//en":                 "XHTML 1.0 Transitional",
+	"-//w3c//dtd xhtml 1.0 frameset//en":                     "XHTML 1.0 Frameset",
+	"-//w3c//dtd xhtml 1.1//en":                              "XHTML 1.1",
+	"-//w3c//dtd xhtml basic 1.0//en":                        "XHTML Basic 1.0",
+	"-//w3c//dtd xhtml basic 1.1//en":                        "XHTML Basic 1.1",
+	"-//w3c//dtd xhtml 1.1 plus mathml 2.0//en":              "XHTML 1.1 plus MathML 2.0",
+	"-//w3c//dtd xhtml 1.1 plus mathml 2.0 plus svg 1.1//en": "XHTML 1.1 plus MathML 2.0 plus SVG 1.1",
+	"-//w3c//dtd xhtml+rdfa 1.0//en":                         "XHTML+RDFa 1.0",
+	"-//w3c//dtd xhtml+rdfa 1.1//en":                         "XHTML+RDFa 1.1",
+	"-//w3c//dtd svg 1.0//en":                                "SVG 1.0",
+	"-//w3c//dtd svg 1.1//en":                                "SVG 1.1",
+	"-//w3c//dtd svg 1.1 basic//en":                          "SVG 1.1 Basic",
+	"-//w3c//dtd svg 1.1 tiny//en":                           "SVG 1.1 Tiny",
+	"-//wapforum//dtd xhtml mobile 1.0//en":                  "XHTML Mobile 1.0",
+	"-//wapforum//dtd xhtml mobile 1.2//en":                  "XHTML Mobile 1.2",
+	"-//w3c//dtd html 3 1995-03-24//en":                      "HTML 3.0",
+	"-//w3o//dtd w3 html 3.0//en":                            "HTML 3.0",
+	"-//ietf//dtd html 3.0//en":                              "HTML 3.0",
+}
+
+// Public identifiers putting browsers in quirks mode, see
+// https://html.spec.whatwg.org/multipage/parsing.html#the-initial-insertion-mode
+var (
+	quirkyPublicIDs = []string{
+		"-//w3o//dtd w3 html strict 3.0//en//",
+		"-/w3c/dtd html 4.0 transitional/en",
+		"html",
+	}
+	quirkySystemIDs = []string{
+		"http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd",
+	}
+	quirkyPublicIDPrefixes = []string{
+		"+//silmaril//dtd html pro v0r11 19970101//",
+		"-//as//dtd html 3.0 aswedit + extensions//",
+		"-//advasoft ltd//dtd html 3.0 aswedit + extensions//",
+		"-//ietf//dtd html 2.0 level 1//",
+		"-//ietf//dtd html 2.0 level 2//",
+		"-//ietf//dtd html 2.0 strict level 1//",
+		"-//ietf//dtd html 2.0 strict level 2//",
+		"-//ietf//dtd html 2.0 strict//",
+		"-//ietf//dtd html 2.0//",
+		"-//ietf//dtd html 2.1e//",
+		"-//ietf//dtd html 3.0//",
+		"-//ietf//dtd html 3.2 final//",
+		"-//ietf//dtd html 3.2//",
+		"-//ietf//dtd html 3//",
+		"-//ietf//dtd html level 0//",
+		"-//ietf//dtd html level 1//",
+		"-//ietf//dtd html level 2//",
+		"-//ietf//dtd html level 3//",
+		"-//ietf//dtd html strict level 0//",
+		"-//ietf//dtd html strict level 1//",
+		"-//ietf//dtd html strict level 2//",
+		"-//ietf//dtd html strict level 3//",
+		"-//ietf//dtd html strict//",
+		"-//ietf//dtd html//",
+		"-//metrius//dtd metrius presentational//",
+		"-//microsoft//dtd internet explorer 2.0 html strict//",
+		"-//microsoft//dtd internet explorer 2.0 html//",
+		"-//microsoft//dtd internet explorer 2.0 tables//",
+		"-//microsoft//dtd internet explorer 3.0 html strict//",
+		"-//microsoft//dtd internet explorer 3.0 html//",
+		"-//microsoft//dtd internet explorer 3.0 tables//",
+		"-//netscape comm. corp.//dtd html//",
+		"-//netscape comm. corp.//dtd strict html//",
+		"-//o'reilly and associates//dtd html 2.0//",
+		"-//o'reilly and associates//dtd html extended 1.0//",
+		"-//o'reilly and associates//dtd html extended relaxed 1.0//",
+		"-//sq//dtd html 2.0 hotmetal + extensions//",
+		"-//softquad software//dtd hotmetal pro 6.0::19990601::extensions to html 4.0//",
+		"-//softquad//dtd hotmetal pro 4.0::19971010::extensions to html 4.0//",
+		"-//spyglass//dtd html 2.0 extended//",
+		"-//sun microsystems corp.//dtd hotjava html//",
+		"-//sun microsystems corp.//dtd hotjava strict html//",
+		"-//w3c//dtd html 3 1995-03-24//",
+		"-//w3c//dtd html 3.2 draft//",
+		"-//w3c//dtd html 3.2 final//",
+		"-//w3c//dtd html 3.2//",
+		"-//w3c//dtd html 3.2s draft//",
+		"-//w3c//dtd html 4.0 frameset//",
+		"-//w3c//dtd html 4.0 transitional//",
+		"-//w3c//dtd html experimental 19960712//",
+		"-//w3c//dtd html experimental 970421//",
+		"-//w3c//dtd w3 html//",
+		"-//w3o//dtd w3 html 3.0//",
+		"-//webtechs//dtd mozilla html 2.0//",
+		"-//webtechs//dtd mozilla html//",
+	}
+	// Prefixes putting browsers in quirks mode without a system
+	// identifier and in limited-quirks mode with one.
+	transitionalPublicIDPrefixes = []string{
+		"-//w3c//dtd html 4.01 frameset//",
+		"-//w3c//dtd html 4.01 transitional//",
+	}
+	limitedQuirkyPublicIDPrefixes = []string{
+		"-//w3c//dtd xhtml 1.0 frameset//",
+		"-//w3c//dtd xhtml 1.0 transitional//",
+	}
+)
+
+// doctype is a parsed DOCTYPE token.
+type doctype struct {
+	name        string
+	publicID    string
+	systemID    string
+	hasPublic   bool
+	hasSystem   bool
+	forceQuirks bool
+}
+
+// documentVersion reads the doctype the document starts with and tells the
+// version it declares and the mode it puts browsers in. Comments and white
+// space may come before the doctype, anything else means there is none.
+func documentVersion(body []byte) *model.HTMLVersion {
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.CommentToken:
+			continue
+		case html.TextToken:
+			if strings.TrimLeft(string(tokenizer.Text()), "\ufeff \t\n\f\r") == "" {
+				continue
+			}
+		case html.DoctypeToken:
+			data := string(tokenizer.Text())
+			d := parseDoctype(data)
+			return &model.HTMLVersion{
+				Name:     d.version(),
+				Doctype:  strings.TrimSpace(data),
+				RootName: d.name,
+				PublicID: d.publicID,
+				SystemID: d.systemID,
+				Mode:     d.mode(),
+			}
+		}
+		return &model.HTMLVersion{Name: undefinedVersion, Mode: model.ModeQuirks}
+	}
+}
+
+// parseDoctype splits the text of a DOCTYPE token into its name and
+// identifiers. Like browsers it forces quirks mode when the name is missing, a
+// keyword isn't followed by a quoted identifier or an identifier isn't closed.
+func parseDoctype(data string) doctype {
+	var d doctype
+	data = strings.TrimLeft(data, " \t\n\f\r")
+	end := strings.IndexAny(data, " \t\n\f\r")
+	if end < 0 {
+		end = len(data)
+	}
+	d.name = strings.ToLower(data[:end])
+	if d.name == "" {
+		d.forceQuirks = true
+		return d
+	}
+	rest := strings.TrimLeft(data[end:], " \t\n\f\r")
+	if rest == "" {
+		return d
+	}
+
+	if len(rest) < 6 {
+		d.forceQuirks = true
+		return d
+	}
+	keyword := strings.ToLower(rest[:6])
+	if keyword != "public" && keyword != "system" {
+		d.forceQuirks = true
+		return d
+	}
+	rest = rest[6:]
+	id, rest, ok, terminated := quotedIdentifier(rest)
+	if !ok {
+		d.forceQuirks = true
+		return d
+	}
+	// The doctype ends within an identifier missing its closing quote.
+	d.forceQuirks = !terminated
+	if keyword == "system" {
+		d.systemID, d.hasSystem = id, true
+		return d
+	}
+	d.publicID, d.hasPublic = id, true
+	if id, _, ok, terminated := quotedIdentifier(rest); ok {
+		d.systemID, d.hasSystem = id, true
+		d.forceQuirks = d.forceQuirks || !terminated
+	}
+	return d
+}
+
+// quotedIdentifier reads an identifier in single or double quotes from the
+// start of s and returns it with the rest of s. terminated is false when the
+// closing quote is missing and the identifier runs to the end of s.
+func quotedIdentifier(s string) (id, rest string, ok, terminated bool) {
+	s = strings.TrimLeft(s, " \t\n\f\r")
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return "", s, false, false
+	}
+	end := strings.IndexByte(s[1:], s[0])
+	if end < 0 {
+		return s[1:], "", true, false
+	}
+	return s[1 : end+1], s[end+2:], true, true
+}
+
+// mode computes the document mode as the initial insertion mode of the
+// WHATWG parser does.
+func (d doctype) mode() string {
+	public := strings.ToLower(d.publicID)
+	system := strings.ToLower(d.systemID)
+	switch {
+	case d.forceQuirks || d.name != "html",
+		containsString(quirkyPublicIDs, public),
+		containsString(quirkySystemIDs, system),
+		hasAnyPrefix(public, quirkyPublicIDPrefixes),
+		!d.hasSystem && hasAnyPrefix(public, transitionalPublicIDPrefixes):
+		return model.ModeQuirks
+	case hasAnyPrefix(public, limitedQuirkyPublicIDPrefixes),
+		d.hasSystem && hasAnyPrefix(public, transitionalPublicIDPrefixes):
+		return model.ModeLimitedQuirks
+	}
+	return model.ModeNoQuirks
+}
+
+// version names the HTML version the doctype declares.
+func (d doctype) version() string {
+	if name, ok := doctypeVersions[strings.ToLower(d.publicID)]; ok {
+		return name
+	}
+	if d.name == "html" && !d.forceQuirks && !d.hasPublic && (!d.hasSystem || d.systemID == "about:legacy-compat") {
+		return "HTML5"
+	}
+	return undefinedVersion
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Doctype Test", func() {
+
+	version := func(body string) *model.HTMLVersion {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		fetcher := &servicefakes.FakeFetcher{}
+		fetcher.FetchReturns(&model.FetchResult{Document: doc, Body: []byte(body), Meta: &model.FetchMeta{}}, nil)
+		response, err := service.NewParserService(fetcher, service.ParserConfig{}).Parse(context.Background(), pageURL)
+		Expect(err).NotTo(HaveOccurred())
+		return response.Version
+	}
+
+	table.DescribeTable("should read the doctype and the document mode",
+		func(body, name, publicID, systemID, mode string) {
+			v := version(body + "<html><head><title>t</title></head><body></body></html>")
+			Expect(v.Name).To(Equal(name))
+			Expect(v.PublicID).To(Equal(publicID))
+			Expect(v.SystemID).To(Equal(systemID))
+			Expect(v.Mode).To(Equal(mode))
+		},
+		table.Entry("html5", `<!DOCTYPE html>`, "HTML5", "", "", model.ModeNoQuirks),
+		table.Entry("html5 after comments", "\ufeff<!-- generated -->\n<!--[if IE]><![endif]-->\n<!doctype HTML>",
+			"HTML5", "", "", model.ModeNoQuirks),
+		table.Entry("legacy compat", `<!DOCTYPE html SYSTEM "about:legacy-compat">`,
+			"HTML5", "", "about:legacy-compat", model.ModeNoQuirks),
+		table.Entry("html 4.01 strict", `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd">`,
+			"HTML 4.01 Strict", "-//W3C//DTD HTML 4.01//EN", "http://www.w3.org/TR/html4/strict.dtd", model.ModeNoQuirks),
+		table.Entry("html 4.01 transitional", `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "http://www.w3.org/TR/html4/loose.dtd">`,
+			"HTML 4.01 Transitional", "-//W3C//DTD HTML 4.01 Transitional//EN", "http://www.w3.org/TR/html4/loose.dtd", model.ModeLimitedQuirks),
+		table.Entry("html 4.01 transitional without system id", `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN">`,
+			"HTML 4.01 Transitional", "-//W3C//DTD HTML 4.01 Transitional//EN", "", model.ModeQuirks),
+		table.Entry("xhtml 1.0 transitional", `<!DOCTYPE html PUBLIC '-//W3C//DTD XHTML 1.0 Transitional//EN' 'http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd'>`,
+			"XHTML 1.0 Transitional", "-//W3C//DTD XHTML 1.0 Transitional//EN", "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd", model.ModeLimitedQuirks),
+		table.Entry("xhtml 1.1", `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.1//EN" "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd">`,
+			"XHTML 1.1", "-//W3C//DTD XHTML 1.1//EN", "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd", model.ModeNoQuirks),
+		table.Entry("mathml and svg", `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.1 plus MathML 2.0 plus SVG 1.1//EN" "http://www.w3.org/2002/04/xhtml-math-svg/xhtml-math-svg.dtd">`,
+			"XHTML 1.1 plus MathML 2.0 plus SVG 1.1", "-//W3C//DTD XHTML 1.1 plus MathML 2.0 plus SVG 1.1//EN", "http://www.w3.org/2002/04/xhtml-math-svg/xhtml-math-svg.dtd", model.ModeNoQuirks),
+		table.Entry("html 3.2", `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN">`,
+			"HTML 3.2", "-//W3C//DTD HTML 3.2 Final//EN", "", model.ModeQuirks),
+		table.Entry("ibm system id", `<!DOCTYPE html SYSTEM "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd">`,
+			"undefined", "", "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd", model.ModeQuirks),
+		table.Entry("other root name", `<!DOCTYPE svg>`, "undefined", "", "", model.ModeQuirks),
+		table.Entry("unterminated public identifier", `<!DOCTYPE html PUBLIC "-//W3C//DTD HTML 4.01//EN>`,
+			"HTML 4.01 Strict", "-//W3C//DTD HTML 4.01//EN", "", model.ModeQuirks),
+		table.Entry("unterminated system identifier", `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd>`,
+			"HTML 4.01 Strict", "-//W3C//DTD HTML 4.01//EN", "http://www.w3.org/TR/html4/strict.dtd", model.ModeQuirks),
+		table.Entry("missing identifier", `<!DOCTYPE html PUBLIC>`, "undefined", "", "", model.ModeQuirks),
+		table.Entry("missing doctype", ``, "undefined", "", "", model.ModeQuirks),
+		table.Entry("doctype after an element", `<p></p><!DOCTYPE html>`, "undefined", "", "", model.ModeQuirks),
+	)
+
+	It("should read the doctype of a document fetched without its body", func() {
+		response := parsePage(&servicefakes.FakeFetcher{}, pageURL,
+			`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd"><html></html>`)
+		Expect(response.Version).To(Equal(&model.HTMLVersion{
+			Name:     "XHTML 1.0 Strict",
+			Doctype:  `html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd"`,
+			RootName: "html",
+			PublicID: "-//W3C//DTD XHTML 1.0 Strict//EN",
+			SystemID: "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd",
+			Mode:     model.ModeNoQuirks,
+		}))
+	})
+})
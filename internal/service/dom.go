@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// uniqueElements appear once per document, they need no position.
+var uniqueElements = map[string]bool{"html": true, "head": true, "body": true}
+
+var cssIdentifier = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// idCounts counts the elements of a document by id.
+type idCounts map[string]int
+
+func countIDs(doc *goquery.Document) idCounts {
+	ids := make(idCounts)
+	doc.Find("[id]").Each(func(i int, s *goquery.Selection) {
+		ids[s.AttrOr("id", "")]++
+	})
+	return ids
+}
+
+// selectorPath returns a CSS selector which locates the element in its
+// document, e.g. "#nav > ul:nth-child(2) > li:nth-child(1) > a". The path
+// starts at the closest ancestor with a usable id used once in the
+// document, or at the root element.
+func selectorPath(s *goquery.Selection, ids idCounts) string {
+	if s.Length() == 0 {
+		return ""
+	}
+	var parts []string
+	for node := s.Get(0); node != nil && node.Type == html.ElementNode; node = node.Parent {
+		if id := attr(node, "id"); cssIdentifier.MatchString(id) && ids[id] == 1 {
+			parts = append(parts, node.Data+"#"+id)
+			break
+		}
+		part := node.Data
+		if index, count := childPosition(node); count > 1 && !uniqueElements[node.Data] {
+			part = fmt.Sprintf("%s:nth-child(%d)", part, index)
+		}
+		parts = append(parts, part)
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
+
+// childPosition returns the 1-based position of the element among the
+// element children of its parent, and the number of those children.
+func childPosition(node *html.Node) (int, int) {
+	if node.Parent == nil {
+		return 1, 1
+	}
+	var index, count int
+	for sibling := node.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type != html.ElementNode {
+			continue
+		}
+		count++
+		if sibling == node {
+			index = count
+		}
+	}
+	return index, count
+}
+
+func attr(node *html.Node, name string) string {
+	for _, a := range node.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
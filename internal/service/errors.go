@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/pkg/errors"
+)
+
+// Kinds of fetch failures. A *FetchError matches its kind with errors.Is.
+var (
+	ErrInvalidURL        = errors.New("invalid url")
+	ErrDNS               = errors.New("dns lookup failed")
+	ErrConnectionRefused = errors.New("connection refused")
+	ErrTimeout           = errors.New("request timed out")
+	ErrTLS               = errors.New("tls handshake failed")
+	ErrNotHTML           = errors.New("content is not html")
+	ErrUpstreamClient    = errors.New("upstream responded with client error")
+	ErrUpstreamServer    = errors.New("upstream responded with server error")
+)
+
+// FetchError is returned when a page can't be fetched or isn't fit for analysis.
+type FetchError struct {
+	Kind       error
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+func (e *FetchError) Error() string {
+	msg := fmt.Sprintf("fetch %s: %s", e.URL, e.Kind)
+	if e.StatusCode != 0 {
+		msg = fmt.Sprintf("%s (status %d)", msg, e.StatusCode)
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err)
+	}
+	return msg
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+func (e *FetchError) Is(target error) bool {
+	return e.Kind == target
+}
+
+// classifyError maps a transport error to one of the fetch failure kinds.
+// It returns nil if the error doesn't belong to any of them.
+func classifyError(err error) error {
+	var (
+		dnsErr      *net.DNSError
+		netErr      net.Error
+		recordErr   tls.RecordHeaderError
+		authErr     x509.UnknownAuthorityError
+		hostnameErr x509.HostnameError
+		certErr     x509.CertificateInvalidError
+	)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	case errors.As(err, &dnsErr):
+		return ErrDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrConnectionRefused
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrTimeout
+	case errors.As(err, &recordErr), errors.As(err, &authErr),
+		errors.As(err, &hostnameErr), errors.As(err, &certErr):
+		return ErrTLS
+	case strings.Contains(err.Error(), "tls:"):
+		return ErrTLS
+	}
+	return nil
+}
+
+// statusError maps an upstream error status to a fetch failure kind.
+func statusError(statusCode int) error {
+	switch {
+	case statusCode >= 500:
+		return ErrUpstreamServer
+	case statusCode >= 400:
+		return ErrUpstreamClient
+	}
+	return nil
+}
+
+// failureCategory maps a link check error to the category reported on a link.
+func failureCategory(err error) string {
+	if errors.Is(err, ErrInvalidURL) {
+		return model.FailureInvalidURL
+	}
+	if errors.Is(err, context.Canceled) {
+		return model.FailureCanceled
+	}
+	switch classifyError(err) {
+	case ErrTimeout:
+		return model.FailureTimeout
+	case ErrDNS:
+		return model.FailureDNS
+	case ErrConnectionRefused:
+		return model.FailureConnection
+	case ErrTLS:
+		return model.FailureTLS
+	}
+	return model.FailureOther
+}
@@ -1,10 +1,14 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
-	"sync"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
@@ -12,50 +16,253 @@ import (
 	"github.com/pkg/errors"
 )
 
+// maxDocumentSize limits how much of the analyzed page is read into memory,
+// longer documents are cut and flagged as truncated.
+const maxDocumentSize = 10 << 20
+
 type Fetcher interface {
-	Fetch(url string) (*goquery.Document, error)
+	Fetch(ctx context.Context, url string) (*model.FetchResult, error)
 	IsAccessible(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error)
 }
 
-type FetcherService struct {
-	client *http.Client
+// rateLimitWait is how long a host is left alone after a 429 response
+// without Retry-After.
+const rateLimitWait = time.Second
 
-	sync.WaitGroup
+// FetcherConfig holds the politeness limits applied to every host.
+type FetcherConfig struct {
+	// HostConcurrency is the number of requests sent to one host at once.
+	// Zero means no limit.
+	HostConcurrency int
+	// HostDelay is the minimum delay between requests to the same host.
+	HostDelay time.Duration
+	// MaxRetryAfter is the longest wait asked by a 429 or Retry-After
+	// response the fetcher accepts before retrying. Longer or missing
+	// waits fail the request, and so do waits the request deadline
+	// doesn't leave time for. It should be well below the 10s link check
+	// timeout.
+	MaxRetryAfter time.Duration
 }
 
-func NewFetcherService(client *http.Client) *FetcherService {
+type FetcherService struct {
+	client  *http.Client
+	config  FetcherConfig
+	limiter *hostLimiter
+}
+
+func NewFetcherService(client *http.Client, config FetcherConfig) *FetcherService {
 	return &FetcherService{
-		client: client,
+		client:  client,
+		config:  config,
+		limiter: newHostLimiter(config.HostConcurrency, config.HostDelay),
 	}
 }
 
-func (p *FetcherService) Fetch(url string) (*goquery.Document, error) {
+func (p *FetcherService) Fetch(ctx context.Context, url string) (*model.FetchResult, error) {
+	target, err := parseTarget(url)
+	if err != nil {
+		return nil, &FetchError{Kind: ErrInvalidURL, URL: url, Err: err}
+	}
+	req, err := http.NewRequest(http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, &FetchError{Kind: ErrInvalidURL, URL: url, Err: err}
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+
+	response, err := p.do(ctx, req)
+	if response != nil && response.Body != nil {
+		defer response.Body.Close()
+	}
+	if err != nil {
+		if kind := classifyError(err); kind != nil {
+			return nil, &FetchError{Kind: kind, URL: url, Err: err}
+		}
+		return nil, errors.Wrap(err, "fetching document failed")
+	}
+	if kind := statusError(response.StatusCode); kind != nil {
+		return nil, &FetchError{Kind: kind, URL: url, StatusCode: response.StatusCode}
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxDocumentSize+1))
+	if err != nil {
+		if kind := classifyError(err); kind != nil {
+			return nil, &FetchError{Kind: kind, URL: url, Err: err}
+		}
+		return nil, errors.Wrap(err, "reading document body failed")
+	}
+	truncated := len(body) > maxDocumentSize
+	if truncated {
+		body = body[:maxDocumentSize]
+	}
+	contentType := response.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	if !isHTML(contentType) {
+		return nil, &FetchError{
+			Kind:       ErrNotHTML,
+			URL:        url,
+			StatusCode: response.StatusCode,
+			Err:        errors.Errorf("unexpected content type %q", contentType),
+		}
+	}
 	// Load the HTML document
-	return goquery.NewDocument(url)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing document failed")
+	}
+	doc.Url = response.Request.URL
+
+	return &model.FetchResult{
+		Document: doc,
+		Body:     body,
+		Meta: &model.FetchMeta{
+			URL:         url,
+			FinalURL:    response.Request.URL.String(),
+			StatusCode:  response.StatusCode,
+			Headers:     response.Header,
+			ContentType: contentType,
+			BodySize:    int64(len(body)),
+			Truncated:   truncated,
+			Redirects:   redirectChain(response),
+		},
+	}, nil
 }
 
+// IsAccessible checks the link with a HEAD request and falls back to GET for
+// servers that don't support HEAD. Transport failures are reported through
+// the Failure field rather than as an error.
 func (p *FetcherService) IsAccessible(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
-	req, err := http.NewRequest(http.MethodGet, pr.Url, nil)
-	req.Header.Set("Content-Type", "application/json")
+	const requestTimeout = 10 * time.Second
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	pr.Method = http.MethodHead
+	response, err := p.check(ctx, http.MethodHead, pr.Url)
+	if err == nil && (response.StatusCode == http.StatusMethodNotAllowed ||
+		response.StatusCode == http.StatusNotImplemented) {
+		pr.Method = http.MethodGet
+		response, err = p.check(ctx, http.MethodGet, pr.Url)
+	}
+	pr.LatencyMs = time.Since(start).Milliseconds()
 	if err != nil {
-		return nil, errors.Wrap(err, "creating preprocess request failed")
+		pr.Result = false
+		pr.Failure = failureCategory(err)
+		return pr, nil
 	}
-	const requestTimeout = 10 * time.Second
-	ctx1, _ := context.WithTimeout(ctx, requestTimeout)
 
-	response, err := p.client.Do(req.WithContext(ctx1))
-	if response != nil && response.Body != nil {
-		defer response.Body.Close()
+	pr.StatusCode = response.StatusCode
+	pr.FinalURL = response.Request.URL.String()
+	pr.Redirects = len(redirectChain(response))
+	pr.ContentType = response.Header.Get("Content-Type")
+	if response.ContentLength > 0 {
+		pr.ContentLength = response.ContentLength
+	}
+	pr.Result = response.StatusCode >= 200 && response.StatusCode < 400
+	switch statusError(response.StatusCode) {
+	case ErrUpstreamClient:
+		if response.StatusCode == http.StatusTooManyRequests {
+			pr.Failure = model.FailureRateLimited
+			break
+		}
+		pr.Failure = model.FailureClientError
+	case ErrUpstreamServer:
+		pr.Failure = model.FailureServerError
+	}
+	return pr, nil
+}
+
+// check sends a single link check request. Only the status line and headers
+// are used, so the body is closed before the response is returned.
+func (p *FetcherService) check(ctx context.Context, method, url string) (*http.Response, error) {
+	target, err := parseTarget(url)
+	if err != nil {
+		return nil, &FetchError{Kind: ErrInvalidURL, URL: url, Err: err}
 	}
+	req, err := http.NewRequest(method, target.String(), nil)
 	if err != nil {
-		return nil, errors.Wrap(err, "creating preprocess api failed")
+		return nil, &FetchError{Kind: ErrInvalidURL, URL: url, Err: err}
 	}
-	_, err = ioutil.ReadAll(response.Body)
+	response, err := p.do(ctx, req)
 	if err != nil {
-		return nil, errors.Wrap(err, "reading preprocess body failed")
+		return nil, err
 	}
-	if response.StatusCode >= 200 || response.StatusCode < 400 {
-		pr.Result = true
+	// drain a little of the body so the connection can be reused
+	io.Copy(ioutil.Discard, io.LimitReader(response.Body, 4<<10))
+	response.Body.Close()
+	return response, nil
+}
+
+// do sends the request within the politeness limits of its host. When the
+// host answers with 429, or with 503 and Retry-After, the host is backed off
+// and the request is retried once if the wait is acceptable.
+func (p *FetcherService) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	for attempt := 0; ; attempt++ {
+		release, err := p.limiter.acquire(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		response, err := p.client.Do(req.WithContext(ctx))
+		release()
+		if err != nil {
+			return response, err
+		}
+
+		wait, asked := retryAfter(response, time.Now())
+		limited := response.StatusCode == http.StatusTooManyRequests
+		unavailable := response.StatusCode == http.StatusServiceUnavailable && asked
+		if !limited && !unavailable {
+			return response, nil
+		}
+		if !asked {
+			wait = rateLimitWait
+		}
+		p.limiter.backoff(host, time.Now().Add(wait))
+		if attempt > 0 || wait > p.config.MaxRetryAfter || !fitsDeadline(ctx, wait) {
+			return response, nil
+		}
+		response.Body.Close()
 	}
-	return pr, nil
+}
+
+// fitsDeadline reports whether a request can still be sent after the wait
+// before the context expires.
+func fitsDeadline(ctx context.Context, wait time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Now().Add(wait).Before(deadline)
+}
+
+// parseTarget validates that the url is an absolute http(s) address.
+func parseTarget(rawURL string) (*url.URL, error) {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, errors.Errorf("unsupported scheme %q", target.Scheme)
+	}
+	if target.Host == "" {
+		return nil, errors.New("missing host")
+	}
+	return target, nil
+}
+
+// isHTML reports whether the content type describes an HTML document.
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// redirectChain returns the URLs the client was redirected through before
+// reaching the final response, in the order they were visited.
+func redirectChain(response *http.Response) []string {
+	var chain []string
+	for req := response.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		chain = append([]string{req.Response.Request.URL.String()}, chain...)
+	}
+	return chain
 }
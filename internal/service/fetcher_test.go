@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("Fetcher Test", func() {
+
+	var (
+		server  *httptest.Server
+		fetcher *service.FetcherService
+		methods []string
+	)
+	BeforeEach(func() {
+		methods = nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<!DOCTYPE html><html><head><title>Page</title></head></html>"))
+		})
+		mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/page", http.StatusMovedPermanently)
+		})
+		mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("{}"))
+		})
+		mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+			methods = append(methods, r.Method)
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		})
+		mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<!DOCTYPE html><html><body><p>"))
+			w.Write(bytes.Repeat([]byte("a"), 11<<20))
+		})
+		mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		server = httptest.NewServer(mux)
+		fetcher = service.NewFetcherService(server.Client(), service.FetcherConfig{})
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("fetching a document", func() {
+		It("should return the document with its metadata", func() {
+			result, err := fetcher.Fetch(context.Background(), server.URL+"/moved")
+			Expect(err).To(BeNil())
+			Expect(result.Document.Find("title").Text()).To(BeEquivalentTo("Page"))
+			Expect(string(result.Body)).To(ContainSubstring("<title>Page</title>"))
+			Expect(result.Meta.FinalURL).To(BeEquivalentTo(server.URL + "/page"))
+			Expect(result.Meta.StatusCode).To(BeEquivalentTo(http.StatusOK))
+			Expect(result.Meta.ContentType).To(BeEquivalentTo("text/html; charset=utf-8"))
+			Expect(result.Meta.Redirects).To(BeEquivalentTo([]string{server.URL + "/moved"}))
+		})
+		It("should flag documents cut at the size limit", func() {
+			result, err := fetcher.Fetch(context.Background(), server.URL+"/large")
+			Expect(err).To(BeNil())
+			Expect(result.Meta.Truncated).To(BeTrue())
+			Expect(result.Meta.BodySize).To(BeEquivalentTo(10 << 20))
+
+			result, err = fetcher.Fetch(context.Background(), server.URL+"/page")
+			Expect(err).To(BeNil())
+			Expect(result.Meta.Truncated).To(BeFalse())
+		})
+		It("should reject invalid urls", func() {
+			_, err := fetcher.Fetch(context.Background(), "ftp://example.com/")
+			Expect(errors.Is(err, service.ErrInvalidURL)).To(BeTrue())
+		})
+		It("should reject non html content", func() {
+			_, err := fetcher.Fetch(context.Background(), server.URL+"/json")
+			Expect(errors.Is(err, service.ErrNotHTML)).To(BeTrue())
+		})
+		It("should report upstream errors with the status code", func() {
+			_, err := fetcher.Fetch(context.Background(), server.URL+"/broken")
+			Expect(errors.Is(err, service.ErrUpstreamServer)).To(BeTrue())
+			var fetchErr *service.FetchError
+			Expect(errors.As(err, &fetchErr)).To(BeTrue())
+			Expect(fetchErr.StatusCode).To(BeEquivalentTo(http.StatusServiceUnavailable))
+		})
+	})
+
+	Describe("checking a link", func() {
+		It("should use HEAD and follow redirects", func() {
+			result, err := fetcher.IsAccessible(context.Background(), &model.WorkerWrapper{Url: server.URL + "/moved"})
+			Expect(err).To(BeNil())
+			Expect(result.Result).To(BeTrue())
+			Expect(result.Method).To(BeEquivalentTo(http.MethodHead))
+			Expect(result.StatusCode).To(BeEquivalentTo(http.StatusOK))
+			Expect(result.FinalURL).To(BeEquivalentTo(server.URL + "/page"))
+			Expect(result.Redirects).To(BeEquivalentTo(1))
+			Expect(result.ContentType).To(BeEquivalentTo("text/html; charset=utf-8"))
+			Expect(result.ContentLength).To(BeEquivalentTo(len("<!DOCTYPE html><html><head><title>Page</title></head></html>")))
+		})
+		It("should fall back to GET when HEAD is not allowed", func() {
+			result, err := fetcher.IsAccessible(context.Background(), &model.WorkerWrapper{Url: server.URL + "/no-head"})
+			Expect(err).To(BeNil())
+			Expect(result.Result).To(BeTrue())
+			Expect(result.Method).To(BeEquivalentTo(http.MethodGet))
+			Expect(methods).To(BeEquivalentTo([]string{http.MethodHead, http.MethodGet}))
+		})
+		It("should categorize error statuses", func() {
+			result, err := fetcher.IsAccessible(context.Background(), &model.WorkerWrapper{Url: server.URL + "/broken"})
+			Expect(err).To(BeNil())
+			Expect(result.Result).To(BeFalse())
+			Expect(result.Failure).To(BeEquivalentTo(model.FailureServerError))
+		})
+		It("should categorize refused connections", func() {
+			closed := httptest.NewServer(http.NotFoundHandler())
+			closed.Close()
+			result, err := fetcher.IsAccessible(context.Background(), &model.WorkerWrapper{Url: closed.URL})
+			Expect(err).To(BeNil())
+			Expect(result.Result).To(BeFalse())
+			Expect(result.Failure).To(BeEquivalentTo(model.FailureConnection))
+		})
+	})
+})
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/PuerkitoBio/goquery"
+)
+
+const defaultEnctype = "application/x-www-form-urlencoded"
+
+var (
+	checkoutHint   = regexp.MustCompile(`(?i)checkout|check out|place order|pay now|payment|billing`)
+	resetHint      = regexp.MustCompile(`(?i)forgot|reset|recover|lost (your )?password`)
+	signupHint     = regexp.MustCompile(`(?i)sign ?up|register|registration|create (an |your )?account|join`)
+	searchHint     = regexp.MustCompile(`(?i)search`)
+	contactHint    = regexp.MustCompile(`(?i)contact|message|enquiry|inquiry|get in touch`)
+	newsletterHint = regexp.MustCompile(`(?i)newsletter|subscribe`)
+	cardField      = regexp.MustCompile(`(?i)card|cc-?num|cvv|cvc|expir`)
+)
+
+// formScan holds what one traversal of a form collects: its fields, the
+// texts of its submit controls and the texts of its leaf elements.
+type formScan struct {
+	inputs  []*model.FormInput
+	submits []string
+	texts   []string
+}
+
+// walkChildren visits the children of s in document order and descends into
+// the children of every element visit returns true for.
+func walkChildren(s *goquery.Selection, visit func(*goquery.Selection) bool) {
+	s.Children().Each(func(i int, si *goquery.Selection) {
+		if visit(si) {
+			walkChildren(si, visit)
+		}
+	})
+}
+
+func scanForm(form *goquery.Selection) *formScan {
+	scan := &formScan{}
+	walkChildren(form, func(s *goquery.Selection) bool {
+		switch goquery.NodeName(s) {
+		case "input":
+			input := formInput(s, strings.ToLower(s.AttrOr("type", "text")))
+			scan.inputs = append(scan.inputs, input)
+			if input.Type == "submit" || input.Type == "image" {
+				scan.submits = append(scan.submits, s.AttrOr("value", s.AttrOr("alt", "")))
+			}
+			return false
+		case "select", "textarea":
+			scan.inputs = append(scan.inputs, formInput(s, goquery.NodeName(s)))
+			return false
+		case "button":
+			// Other buttons, e.g. to show the password or clear the form,
+			// say nothing about what the form is for.
+			if !submitButton(s) {
+				return false
+			}
+			scan.submits = append(scan.submits, s.Text())
+		}
+		if s.Children().Length() == 0 {
+			if text := s.Text(); len(strings.TrimSpace(text)) > 0 {
+				scan.texts = append(scan.texts, text)
+			}
+		}
+		return true
+	})
+	return scan
+}
+
+// submitButton reports whether the button submits its form.
+func submitButton(s *goquery.Selection) bool {
+	buttonType := strings.ToLower(strings.TrimSpace(s.AttrOr("type", "")))
+	return buttonType == "" || buttonType == "submit"
+}
+
+func formInput(s *goquery.Selection, fieldType string) *model.FormInput {
+	_, required := s.Attr("required")
+	return &model.FormInput{
+		Type:         fieldType,
+		Name:         s.AttrOr("name", ""),
+		ID:           s.AttrOr("id", ""),
+		Required:     required,
+		Autocomplete: strings.ToLower(strings.TrimSpace(s.AttrOr("autocomplete", ""))),
+	}
+}
+
+// visibleField reports whether the user fills the field in, as opposed to
+// hidden fields, buttons and toggles.
+func visibleField(input *model.FormInput) bool {
+	switch input.Type {
+	case "hidden", "submit", "button", "reset", "image", "checkbox", "radio":
+		return false
+	}
+	return true
+}
+
+// inventoryForms describes every form of the document and returns the login
+// detection of the form most likely to be a login form.
+func (p *ParserService) inventoryForms(doc *goquery.Document) ([]*model.Form, *model.LoginDetection) {
+	lang := documentLanguage(doc)
+	keywords := p.config.Keywords.Keywords().ForLanguage(lang)
+	base := documentBase(doc)
+	ids := countIDs(doc)
+
+	forms := make([]*model.Form, 0)
+	var best *model.LoginDetection
+	doc.Find("form").Each(func(i int, s *goquery.Selection) {
+		scan := scanForm(s)
+		detection := scoreLoginForm(scan, s, p.config.LoginThreshold, keywords)
+		if best == nil || detection.Score > best.Score {
+			best = detection
+		}
+
+		form := &model.Form{
+			Selector:   selectorPath(s, ids),
+			Method:     strings.ToLower(strings.TrimSpace(s.AttrOr("method", "get"))),
+			Action:     documentURL(doc).String(),
+			Enctype:    strings.ToLower(strings.TrimSpace(s.AttrOr("enctype", defaultEnctype))),
+			LoginScore: detection.Score,
+			Inputs:     scan.inputs,
+		}
+		if action := strings.TrimSpace(s.AttrOr("action", "")); action != "" {
+			form.Action = action
+			if target, err := resolveHref(base, action); err == nil {
+				form.Action = target.String()
+			}
+		}
+		if form.Inputs == nil {
+			form.Inputs = []*model.FormInput{}
+		}
+		form.Kind = classifyForm(scan, s, detection, form.Action)
+		forms = append(forms, form)
+	})
+	if best == nil {
+		best = &model.LoginDetection{Threshold: p.config.LoginThreshold, Signals: []string{}}
+	}
+	best.Language = lang
+	return forms, best
+}
+
+// classifyForm tells the kind of a form from its fields, its texts and where
+// it submits to. Card fields and password fields are the strongest signals,
+// texts decide between forms with similar fields.
+func classifyForm(scan *formScan, form *goquery.Selection, login *model.LoginDetection, action string) string {
+	hints := strings.Join(append(append([]string{action, form.AttrOr("id", ""), form.AttrOr("class", ""),
+		form.AttrOr("name", "")}, scan.submits...), scan.texts...), " ")
+
+	var visible, passwords, newPasswords, emails, textareas, searches, cards int
+	for _, input := range scan.inputs {
+		if !visibleField(input) {
+			continue
+		}
+		visible++
+		switch {
+		case input.Type == "password":
+			passwords++
+			if input.Autocomplete == "new-password" {
+				newPasswords++
+			}
+		case input.Type == "email" || input.Autocomplete == "email":
+			emails++
+		case input.Type == "textarea":
+			textareas++
+		case input.Type == "search" || searchField.MatchString(input.Name):
+			searches++
+		}
+		if strings.HasPrefix(input.Autocomplete, "cc-") || cardField.MatchString(input.Name) {
+			cards++
+		}
+	}
+	if strings.EqualFold(form.AttrOr("role", ""), "search") {
+		searches++
+	}
+
+	switch {
+	case cards > 0 || (passwords == 0 && checkoutHint.MatchString(hints)):
+		return model.FormCheckout
+	case passwords == newPasswords && resetHint.MatchString(hints) && (passwords > 0 || visible <= 2):
+		return model.FormPasswordReset
+	case newPasswords > 0 || passwords > 1 || (signupHint.MatchString(hints) && (passwords > 0 || visible > 2) && textareas == 0):
+		return model.FormSignup
+	case login.IsLogin || passwords > 0:
+		return model.FormLogin
+	case searches > 0 || (visible == 1 && searchHint.MatchString(hints)):
+		return model.FormSearch
+	case textareas > 0 || (visible > 1 && contactHint.MatchString(hints)):
+		return model.FormContact
+	case (emails > 0 && visible <= 2) || newsletterHint.MatchString(hints):
+		return model.FormNewsletter
+	}
+	return model.FormOther
+}
@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"fmt"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Form inventory Test", func() {
+
+	It("should describe every form", func() {
+		response := parsePage(&servicefakes.FakeFetcher{}, "https://www.example.com/account/",
+			`<!DOCTYPE html><html><head><base href="/app/"></head><body>
+			<form id="login" method="POST" action="session">
+				<input type="email" name="email" required autocomplete="Username">
+				<input type="password" name="password" required autocomplete="current-password">
+				<input type="hidden" name="csrf" value="token">
+				<button>Log in</button>
+			</form>
+			<form enctype="multipart/form-data">
+				<select name="topic"><option>Billing</option></select>
+				<textarea name="body"></textarea>
+			</form>
+			</body></html>`)
+
+		Expect(response.Forms).To(HaveLen(2))
+		login := response.Forms[0]
+		Expect(login.Selector).To(Equal("form#login"))
+		Expect(login.Kind).To(Equal(model.FormLogin))
+		Expect(login.Method).To(Equal("post"))
+		Expect(login.Action).To(Equal("https://www.example.com/app/session"))
+		Expect(login.Enctype).To(Equal("application/x-www-form-urlencoded"))
+		Expect(login.LoginScore).To(Equal(response.LoginDetection.Score))
+		Expect(login.Inputs).To(Equal([]*model.FormInput{
+			{Type: "email", Name: "email", Required: true, Autocomplete: "username"},
+			{Type: "password", Name: "password", Required: true, Autocomplete: "current-password"},
+			{Type: "hidden", Name: "csrf"},
+		}))
+
+		other := response.Forms[1]
+		Expect(other.Method).To(Equal("get"))
+		Expect(other.Action).To(Equal("https://www.example.com/account/"))
+		Expect(other.Enctype).To(Equal("multipart/form-data"))
+		Expect(other.Inputs).To(Equal([]*model.FormInput{
+			{Type: "select", Name: "topic"},
+			{Type: "textarea", Name: "body"},
+		}))
+	})
+
+	It("should list no forms for a page without forms", func() {
+		response := parsePage(&servicefakes.FakeFetcher{}, pageURL, `<!DOCTYPE html><html><body></body></html>`)
+		Expect(response.Forms).To(BeEmpty())
+		Expect(response.Login).To(BeFalse())
+	})
+
+	table.DescribeTable("should classify forms",
+		func(form, kind string) {
+			response := parsePage(&servicefakes.FakeFetcher{}, pageURL,
+				fmt.Sprintf(`<!DOCTYPE html><html><body>%s</body></html>`, form))
+
+			Expect(response.Forms).To(HaveLen(1))
+			Expect(response.Forms[0].Kind).To(Equal(kind))
+		},
+		table.Entry("login", `<form>
+				<input name="username"><input type="password" name="password">
+				<a href="/forgot">Forgot your password?</a>
+				<button>Sign in</button>
+			</form>`, model.FormLogin),
+		table.Entry("signup", `<form action="/register">
+				<input name="name"><input type="email" name="email">
+				<input type="password" autocomplete="new-password">
+				<button>Create account</button>
+			</form>`, model.FormSignup),
+		table.Entry("contact with other buttons", `<form>
+				<h2>Contact us</h2>
+				<input name="name"><input type="email" name="email"><input type="tel" name="phone">
+				<button type="button">Join our team</button>
+				<button type="reset">Clear</button>
+				<button type="submit">Send</button>
+			</form>`, model.FormContact),
+		table.Entry("search", `<form action="/search">
+				<input type="search" name="q"><button>Go</button>
+			</form>`, model.FormSearch),
+		table.Entry("newsletter", `<form>
+				<input type="email" name="email" placeholder="Your email">
+				<button>Subscribe</button>
+			</form>`, model.FormNewsletter),
+		table.Entry("contact", `<form action="/contact">
+				<input name="name"><input type="email" name="email">
+				<textarea name="message"></textarea>
+				<button>Send</button>
+			</form>`, model.FormContact),
+		table.Entry("checkout", `<form action="/order">
+				<input name="cardnumber" autocomplete="cc-number">
+				<input name="exp" autocomplete="cc-exp"><input name="cvc" autocomplete="cc-csc">
+				<button>Pay now</button>
+			</form>`, model.FormCheckout),
+		table.Entry("password reset", `<form action="/password/reset">
+				<label>Email</label><input type="email" name="email">
+				<button>Reset password</button>
+			</form>`, model.FormPasswordReset),
+		table.Entry("other", `<form>
+				<input type="checkbox" name="accept"><button>Continue</button>
+			</form>`, model.FormOther),
+	)
+})
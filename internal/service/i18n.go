@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/text/language"
+)
+
+// maxAlternateFetches bounds the alternates fetched to look for their
+// return links.
+const maxAlternateFetches = 10
+
+type alternatesContextKey struct{}
+
+// WithAlternates returns a context whose analyses fetch the hreflang
+// alternates of the page, even when FetchAlternates isn't set.
+func WithAlternates(ctx context.Context) context.Context {
+	return context.WithValue(ctx, alternatesContextKey{}, true)
+}
+
+func alternatesAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(alternatesContextKey{}).(bool)
+	return allowed
+}
+
+// rtlScripts are the scripts written from right to left.
+var rtlScripts = stringSet("Adlm Arab Hebr Mand Nkoo Rohg Samr Syrc Thaa")
+
+// parseLanguage parses a BCP 47 tag. Tags written with underscores, e.g.
+// "en_US", or using a deprecated subtag, e.g. "en-UK", are rejected with the
+// fixed tag as suggestion. Tags valid as written, e.g. "tl" or "zh-cmn-Hans",
+// are accepted even if a canonical form exists.
+func parseLanguage(value string) (tag language.Tag, suggestion string, ok bool) {
+	tag, err := language.Raw.Parse(value)
+	if err != nil {
+		return language.Und, "", false
+	}
+	if preferred, err := language.Deprecated.Canonicalize(tag); err == nil && preferred != tag {
+		return tag, preferred.String(), false
+	}
+	if strings.Contains(value, "_") {
+		return tag, tag.String(), false
+	}
+	return tag, "", true
+}
+
+func invalidLanguage(value, suggestion string) string {
+	message := fmt.Sprintf("%q is not a valid BCP 47 language tag", value)
+	if suggestion != "" {
+		message = fmt.Sprintf("%s, use %q", message, suggestion)
+	}
+	return message
+}
+
+// internationalization collects the language attributes and hreflang
+// alternates of the page and validates them. The alternates are fetched
+// for their return links when FetchAlternates is set or the context allows
+// it.
+func (p *ParserService) internationalization(ctx context.Context, result *model.FetchResult) *model.Internationalization {
+	doc := result.Document
+	root := doc.Find("html").First()
+	i18n := &model.Internationalization{
+		Lang:       strings.TrimSpace(root.AttrOr("lang", "")),
+		Dir:        strings.ToLower(strings.TrimSpace(root.AttrOr("dir", ""))),
+		Alternates: make([]*model.Alternate, 0),
+		Issues:     make([]*model.I18nIssue, 0),
+	}
+	if result.Meta != nil {
+		i18n.ContentLanguage = strings.TrimSpace(http.Header(result.Meta.Headers).Get("Content-Language"))
+	}
+	ids := countIDs(doc)
+	report := func(code, severity, message string, s *goquery.Selection, url string) {
+		issue := &model.I18nIssue{Code: code, Severity: severity, Message: message, URL: url}
+		if s != nil {
+			issue.Selector = selectorPath(s, ids)
+		}
+		i18n.Issues = append(i18n.Issues, issue)
+	}
+
+	checkLanguage(i18n, root, report)
+
+	page := documentURL(doc)
+	self := map[string]bool{normalizeURL(page.String()): true}
+	if canonical, ok := doc.Find(`link[rel="canonical"][href]`).First().Attr("href"); ok {
+		if target, err := resolveHref(documentBase(doc), strings.TrimSpace(canonical)); err == nil {
+			self[normalizeURL(target.String())] = true
+		}
+	}
+
+	base := documentBase(doc)
+	seen := make(map[string]*model.Alternate)
+	selfReferenced, xDefault := false, false
+	doc.Find(`link[hreflang]`).Each(func(i int, s *goquery.Selection) {
+		if !containsString(strings.Fields(strings.ToLower(s.AttrOr("rel", ""))), "alternate") {
+			return
+		}
+		alternate := &model.Alternate{
+			Hreflang: strings.TrimSpace(s.AttrOr("hreflang", "")),
+			Href:     strings.TrimSpace(s.AttrOr("href", "")),
+			Selector: selectorPath(s, ids),
+		}
+		if target, err := resolveHref(base, alternate.Href); err == nil && alternate.Href != "" {
+			alternate.Url = target.String()
+		}
+		i18n.Alternates = append(i18n.Alternates, alternate)
+
+		key := strings.ToLower(alternate.Hreflang)
+		if strings.EqualFold(alternate.Hreflang, model.XDefault) {
+			xDefault = true
+		} else if tag, suggestion, ok := parseLanguage(alternate.Hreflang); ok {
+			alternate.Language = tag.String()
+			key = strings.ToLower(alternate.Language)
+		} else {
+			report(model.HreflangInvalid, model.SeverityHigh, invalidLanguage(alternate.Hreflang, suggestion), s, alternate.Url)
+		}
+		if previous, ok := seen[key]; ok {
+			report(model.HreflangDuplicate, model.SeverityMedium,
+				fmt.Sprintf("hreflang %q is already used by %s", alternate.Hreflang, previous.Url), s, alternate.Url)
+		} else {
+			seen[key] = alternate
+		}
+		if self[normalizeURL(alternate.Url)] {
+			selfReferenced = true
+		}
+	})
+	if len(i18n.Alternates) == 0 {
+		return i18n
+	}
+	if !selfReferenced {
+		report(model.HreflangMissingSelf, model.SeverityMedium, "no hreflang alternate refers to the page itself", nil, page.String())
+	}
+	if !xDefault {
+		report(model.HreflangMissingXDefault, model.SeverityLow, "no alternate has hreflang=\"x-default\"", nil, "")
+	}
+	if p.config.FetchAlternates || alternatesAllowed(ctx) {
+		p.checkReturnLinks(ctx, i18n, self)
+	}
+	return i18n
+}
+
+// checkLanguage validates the lang and dir of the html element against
+// each other and the Content-Language header.
+func checkLanguage(i18n *model.Internationalization, root *goquery.Selection, report func(code, severity, message string, s *goquery.Selection, url string)) {
+	if i18n.Dir != "" && i18n.Dir != "ltr" && i18n.Dir != "rtl" && i18n.Dir != "auto" {
+		report(model.DirInvalid, model.SeverityMedium, fmt.Sprintf("dir %q is none of ltr, rtl and auto", i18n.Dir), root, "")
+	}
+	if i18n.Lang == "" {
+		return
+	}
+	tag, suggestion, ok := parseLanguage(i18n.Lang)
+	if !ok {
+		report(model.LangInvalid, model.SeverityMedium, invalidLanguage(i18n.Lang, suggestion), root, "")
+		return
+	}
+	if script, confidence := tag.Script(); confidence >= language.High && rtlScripts[script.String()] && i18n.Dir != "rtl" {
+		report(model.DirMissing, model.SeverityMedium,
+			fmt.Sprintf("the page is in %s, written right to left, but has no dir=\"rtl\"", tag), root, "")
+	}
+	if i18n.ContentLanguage == "" {
+		return
+	}
+	// compare the canonical languages, "tl" and "fil" are the same
+	canonical, _ := language.Default.Canonicalize(tag)
+	base, _ := canonical.Base()
+	for _, value := range strings.Split(i18n.ContentLanguage, ",") {
+		if header, err := language.Parse(strings.TrimSpace(value)); err == nil {
+			if headerBase, _ := header.Base(); headerBase == base {
+				return
+			}
+		}
+	}
+	report(model.LangMismatch, model.SeverityLow,
+		fmt.Sprintf("the page is in %s but the Content-Language header says %s", tag, i18n.ContentLanguage), root, "")
+}
+
+// checkReturnLinks fetches the first alternates and reports those which
+// don't link back to the page. Alternates past maxAlternateFetches aren't
+// fetched and mark the return links as truncated.
+func (p *ParserService) checkReturnLinks(ctx context.Context, i18n *model.Internationalization, self map[string]bool) {
+	ctx, cancel := p.checkContext(ctx)
+	defer cancel()
+
+	var urls []string
+	returns := make(map[string]*bool)
+	for _, alternate := range i18n.Alternates {
+		target := normalizeURL(alternate.Url)
+		if alternate.Url == "" || self[target] || !checkable(alternate.Url) {
+			continue
+		}
+		if _, ok := returns[target]; ok {
+			continue
+		}
+		if len(urls) >= maxAlternateFetches {
+			i18n.ReturnLinksTruncated = true
+			continue
+		}
+		returns[target] = nil
+		urls = append(urls, alternate.Url)
+	}
+	for index, returnLink := range p.fetchReturnLinks(ctx, urls, self) {
+		returns[normalizeURL(urls[index])] = returnLink
+	}
+
+	for _, alternate := range i18n.Alternates {
+		returnLink := returns[normalizeURL(alternate.Url)]
+		if returnLink == nil {
+			continue
+		}
+		alternate.ReturnLink = returnLink
+		if !*returnLink {
+			i18n.Issues = append(i18n.Issues, &model.I18nIssue{
+				Code:     model.HreflangMissingReturn,
+				Severity: model.SeverityHigh,
+				Message:  fmt.Sprintf("the %s alternate doesn't link back to the page", alternate.Hreflang),
+				Selector: alternate.Selector,
+				URL:      alternate.Url,
+			})
+		}
+	}
+}
+
+// fetchReturnLinks fetches the alternates through a pool of WorkerCount
+// workers and tells for each whether it links back to one of the urls of the
+// page, nil when it couldn't be fetched before the context was done.
+func (p *ParserService) fetchReturnLinks(ctx context.Context, urls []string, self map[string]bool) []*bool {
+	returnLinks := make([]*bool, len(urls))
+	runPool(ctx, p.config.WorkerCount, len(urls), func(index int) {
+		result, err := p.fetcher.Fetch(ctx, urls[index])
+		if err == nil && result != nil && result.Document != nil {
+			returnLink := linksBack(result.Document, self)
+			returnLinks[index] = &returnLink
+		}
+	})
+	return returnLinks
+}
+
+// linksBack reports whether the document has a hreflang alternate pointing
+// to one of the urls.
+func linksBack(doc *goquery.Document, urls map[string]bool) bool {
+	base := documentBase(doc)
+	found := false
+	doc.Find(`link[hreflang][href]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if !containsString(strings.Fields(strings.ToLower(s.AttrOr("rel", ""))), "alternate") {
+			return true
+		}
+		if target, err := resolveHref(base, strings.TrimSpace(s.AttrOr("href", ""))); err == nil {
+			found = urls[normalizeURL(target.String())]
+		}
+		return !found
+	})
+	return found
+}
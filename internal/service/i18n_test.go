@@ -0,0 +1,227 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Internationalization Test", func() {
+
+	const page = "https://www.example.com/en/"
+
+	fetchResult := func(page, html string, headers http.Header) *model.FetchResult {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		Expect(err).To(BeNil())
+		doc.Url, _ = url.Parse(page)
+		return &model.FetchResult{Document: doc, Meta: &model.FetchMeta{URL: page, FinalURL: page, Headers: headers}}
+	}
+
+	alternates := func(links ...string) string {
+		return fmt.Sprintf(`<!DOCTYPE html><html lang="en"><head>%s</head><body></body></html>`, strings.Join(links, ""))
+	}
+
+	issueCodes := func(i18n *model.Internationalization) []string {
+		codes := []string{}
+		for _, issue := range i18n.Issues {
+			codes = append(codes, issue.Code)
+		}
+		return codes
+	}
+
+	It("should collect the language attributes and the alternates", func() {
+		fetcher := &servicefakes.FakeFetcher{}
+		fetcher.FetchReturns(fetchResult(page, `<!DOCTYPE html><html lang="en-GB" dir="ltr"><head>
+			<link rel="alternate" hreflang="en-GB" href="/en/">
+			<link rel="alternate" hreflang="de-AT" href="https://www.example.com/at/">
+			<link rel="alternate" hreflang="x-default" href="https://www.example.com/">
+			<link rel="stylesheet" hreflang="en" href="/style.css">
+			</head><body></body></html>`, http.Header{"Content-Language": {"en-GB"}}), nil)
+
+		response, err := service.NewParserService(fetcher, service.ParserConfig{}).Parse(context.Background(), page)
+		Expect(err).To(BeNil())
+
+		i18n := response.I18n
+		Expect(i18n.Lang).To(Equal("en-GB"))
+		Expect(i18n.Dir).To(Equal("ltr"))
+		Expect(i18n.ContentLanguage).To(Equal("en-GB"))
+		Expect(i18n.Alternates).To(Equal([]*model.Alternate{
+			{Hreflang: "en-GB", Href: "/en/", Url: page, Selector: "html > head > link:nth-child(1)", Language: "en-GB"},
+			{Hreflang: "de-AT", Href: "https://www.example.com/at/", Url: "https://www.example.com/at/",
+				Selector: "html > head > link:nth-child(2)", Language: "de-AT"},
+			{Hreflang: "x-default", Href: "https://www.example.com/", Url: "https://www.example.com/",
+				Selector: "html > head > link:nth-child(3)"},
+		}))
+		Expect(i18n.Issues).To(BeEmpty())
+		Expect(fetcher.FetchCallCount()).To(Equal(1))
+	})
+
+	table.DescribeTable("should validate the alternates",
+		func(html string, codes ...string) {
+			response := parsePage(&servicefakes.FakeFetcher{}, page, html)
+			Expect(issueCodes(response.I18n)).To(Equal(codes))
+		},
+		table.Entry("without alternates", alternates()),
+		table.Entry("valid tags", alternates(
+			`<link rel="alternate" hreflang="en" href="/en/">`,
+			`<link rel="alternate" hreflang="zh-Hant-TW" href="/tw/">`,
+			`<link rel="alternate" hreflang="es-419" href="/latam/">`,
+			`<link rel="alternate" hreflang="tl" href="/ph/">`,
+			`<link rel="alternate" hreflang="zh-cmn-Hans" href="/cn/">`,
+			`<link rel="alternate" hreflang="X-Default" href="/">`)),
+		table.Entry("invalid tags", alternates(
+			`<link rel="alternate" hreflang="en" href="/en/">`,
+			`<link rel="alternate" hreflang="en-UK" href="/uk/">`,
+			`<link rel="alternate" hreflang="en_US" href="/us/">`,
+			`<link rel="alternate" hreflang="gb" href="/gb/">`,
+			`<link rel="alternate" hreflang="x-default" href="/">`),
+			model.HreflangInvalid, model.HreflangInvalid, model.HreflangInvalid),
+		table.Entry("duplicates", alternates(
+			`<link rel="alternate" hreflang="en" href="/en/">`,
+			`<link rel="alternate" hreflang="de" href="/de/">`,
+			`<link rel="alternate" hreflang="DE" href="/at/">`,
+			`<link rel="alternate" hreflang="x-default" href="/">`,
+			`<link rel="alternate" hreflang="x-default" href="/en/">`),
+			model.HreflangDuplicate, model.HreflangDuplicate),
+		table.Entry("no self-reference nor x-default", alternates(
+			`<link rel="alternate" hreflang="de" href="/de/">`),
+			model.HreflangMissingSelf, model.HreflangMissingXDefault),
+		table.Entry("self-reference by the canonical url", alternates(
+			`<link rel="canonical" href="https://www.example.com/en/index.html">`,
+			`<link rel="alternate" hreflang="en" href="/en/index.html">`,
+			`<link rel="alternate" hreflang="x-default" href="/">`)),
+	)
+
+	table.DescribeTable("should validate the language of the page",
+		func(attributes, contentLanguage string, codes ...string) {
+			fetcher := &servicefakes.FakeFetcher{}
+			fetcher.FetchReturns(fetchResult(page, fmt.Sprintf(`<!DOCTYPE html><html %s><body></body></html>`, attributes),
+				http.Header{"Content-Language": {contentLanguage}}), nil)
+
+			response, err := service.NewParserService(fetcher, service.ParserConfig{}).Parse(context.Background(), page)
+			Expect(err).To(BeNil())
+			Expect(issueCodes(response.I18n)).To(Equal(codes))
+		},
+		table.Entry("valid", `lang="de-CH"`, "de"),
+		table.Entry("one of the header languages", `lang="fr"`, "de, fr"),
+		table.Entry("language with a canonical form", `lang="tl"`, "fil"),
+		table.Entry("extended language", `lang="zh-cmn-Hans"`, "zh-cmn"),
+		table.Entry("other header language", `lang="fr"`, "de", model.LangMismatch),
+		table.Entry("invalid lang", `lang="english"`, "", model.LangInvalid),
+		table.Entry("deprecated lang", `lang="iw" dir="rtl"`, "", model.LangInvalid),
+		table.Entry("invalid dir", `lang="en" dir="left"`, "", model.DirInvalid),
+		table.Entry("right to left language", `lang="ar"`, "", model.DirMissing),
+		table.Entry("right to left direction", `lang="he" dir="rtl"`, ""),
+	)
+
+	Context("when fetching the alternates is allowed", func() {
+
+		var fetcher *servicefakes.FakeFetcher
+
+		BeforeEach(func() {
+			fetcher = &servicefakes.FakeFetcher{}
+			pages := map[string]string{
+				page: alternates(
+					`<link rel="alternate" hreflang="en" href="/en/">`,
+					`<link rel="alternate" hreflang="de" href="/de/">`,
+					`<link rel="alternate" hreflang="fr" href="/fr/">`,
+					`<link rel="alternate" hreflang="x-default" href="/de/">`),
+				"https://www.example.com/de/": alternates(
+					`<link rel="alternate" hreflang="de" href="/de/">`,
+					`<link rel="alternate" hreflang="en" href="https://www.example.com/en/">`),
+				"https://www.example.com/fr/": alternates(
+					`<link rel="alternate" hreflang="fr" href="/fr/">`),
+			}
+			fetcher.FetchCalls(func(ctx context.Context, target string) (*model.FetchResult, error) {
+				return fetchResult(target, pages[target], nil), nil
+			})
+		})
+
+		It("should check that the alternates link back", func() {
+			response, err := service.NewParserService(fetcher, service.ParserConfig{FetchAlternates: true}).
+				Parse(context.Background(), page)
+			Expect(err).To(BeNil())
+
+			i18n := response.I18n
+			Expect(i18n.Alternates[0].ReturnLink).To(BeNil())
+			Expect(*i18n.Alternates[1].ReturnLink).To(BeTrue())
+			Expect(*i18n.Alternates[2].ReturnLink).To(BeFalse())
+			Expect(*i18n.Alternates[3].ReturnLink).To(BeTrue())
+			Expect(i18n.Issues).To(Equal([]*model.I18nIssue{{
+				Code:     model.HreflangMissingReturn,
+				Severity: model.SeverityHigh,
+				Message:  "the fr alternate doesn't link back to the page",
+				Selector: "html > head > link:nth-child(3)",
+				URL:      "https://www.example.com/fr/",
+			}}))
+			// the page and each alternate are fetched once
+			Expect(fetcher.FetchCallCount()).To(Equal(3))
+		})
+
+		It("should not fetch the alternates by default", func() {
+			response, err := service.NewParserService(fetcher, service.ParserConfig{}).Parse(context.Background(), page)
+			Expect(err).To(BeNil())
+
+			Expect(response.I18n.Alternates[1].ReturnLink).To(BeNil())
+			Expect(fetcher.FetchCallCount()).To(Equal(1))
+		})
+
+		It("should fetch the alternates when the request allows it", func() {
+			ctx := service.WithAlternates(context.Background())
+			response, err := service.NewParserService(fetcher, service.ParserConfig{}).Parse(ctx, page)
+			Expect(err).To(BeNil())
+
+			Expect(*response.I18n.Alternates[1].ReturnLink).To(BeTrue())
+			Expect(response.I18n.ReturnLinksTruncated).To(BeFalse())
+			Expect(fetcher.FetchCallCount()).To(Equal(3))
+		})
+
+		It("should fetch the alternates concurrently up to the limit", func() {
+			links := []string{`<link rel="alternate" hreflang="en" href="/en/">`}
+			for i := 0; i < 12; i++ {
+				links = append(links, fmt.Sprintf(`<link rel="alternate" hreflang="de-x-%d" href="/de/%d/">`, i, i))
+			}
+			var mu sync.Mutex
+			var running, concurrent int
+			fetcher.FetchCalls(func(ctx context.Context, target string) (*model.FetchResult, error) {
+				if target == page {
+					return fetchResult(target, alternates(links...), nil), nil
+				}
+				mu.Lock()
+				running++
+				if running > concurrent {
+					concurrent = running
+				}
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return fetchResult(target, alternates(`<link rel="alternate" hreflang="en" href="/en/">`), nil), nil
+			})
+
+			response, err := service.NewParserService(fetcher, service.ParserConfig{WorkerCount: 4, FetchAlternates: true}).
+				Parse(context.Background(), page)
+			Expect(err).To(BeNil())
+
+			i18n := response.I18n
+			Expect(*i18n.Alternates[10].ReturnLink).To(BeTrue())
+			Expect(i18n.Alternates[11].ReturnLink).To(BeNil())
+			Expect(i18n.ReturnLinksTruncated).To(BeTrue())
+			Expect(fetcher.FetchCallCount()).To(Equal(11))
+			Expect(concurrent).To(BeNumerically(">", 1))
+		})
+	})
+})
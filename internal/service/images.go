@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultMaxImageSize is the size in bytes from which an image is oversized.
+const DefaultMaxImageSize = 1 << 20
+
+// images collects the image candidates of the document, checks them like
+// links and reports the images lacking alternative text or dimensions and
+// the broken and oversized ones.
+func (p *ParserService) images(ctx context.Context, doc *goquery.Document) *model.ImageInventory {
+	inventory := &model.ImageInventory{
+		Images: make([]*model.Image, 0),
+		Issues: make([]*model.ImageIssue, 0),
+	}
+	issue := func(code, url, selector, message string) {
+		inventory.Issues = append(inventory.Issues, &model.ImageIssue{
+			Code:     code,
+			Url:      url,
+			Selector: selector,
+			Message:  message,
+		})
+	}
+
+	base := documentBase(doc)
+	ids := countIDs(doc)
+	doc.Find("img").Each(func(i int, s *goquery.Selection) {
+		image := model.Image{
+			Width:    strings.TrimSpace(s.AttrOr("width", "")),
+			Height:   strings.TrimSpace(s.AttrOr("height", "")),
+			Loading:  strings.ToLower(strings.TrimSpace(s.AttrOr("loading", ""))),
+			Selector: selectorPath(s, ids),
+		}
+		if alt, ok := s.Attr("alt"); ok {
+			image.Alt = &alt
+		}
+		add := func(source, src, descriptor string) {
+			candidate := image
+			candidate.Source = source
+			candidate.Src = src
+			candidate.Url = src
+			candidate.Descriptor = descriptor
+			if target, err := resolveHref(base, src); err == nil {
+				candidate.Url = target.String()
+			}
+			inventory.Images = append(inventory.Images, &candidate)
+		}
+
+		if src := strings.TrimSpace(s.AttrOr("src", "")); src != "" {
+			add(model.ImageSourceImg, src, "")
+		}
+		for _, candidate := range parseSrcset(s.AttrOr("srcset", "")) {
+			add(model.ImageSourceSrcset, candidate[0], candidate[1])
+		}
+		if parent := s.Parent(); goquery.NodeName(parent) == "picture" {
+			parent.ChildrenFiltered("source").Each(func(i int, source *goquery.Selection) {
+				for _, candidate := range parseSrcset(source.AttrOr("srcset", "")) {
+					add(model.ImageSourcePicture, candidate[0], candidate[1])
+				}
+			})
+		}
+
+		if image.Alt == nil && !ariaHidden(s) && !strings.EqualFold(s.AttrOr("role", ""), "presentation") {
+			issue(model.ImageMissingAlt, "", image.Selector, "the image has no alt attribute")
+		}
+		if image.Width == "" || image.Height == "" {
+			issue(model.ImageMissingDimensions, "", image.Selector,
+				"the image has no width and height, the layout shifts when it loads")
+		}
+	})
+
+	var urls []string
+	for _, image := range inventory.Images {
+		if checkable(image.Url) {
+			urls = append(urls, image.Url)
+		}
+	}
+	results := p.checkUnique(ctx, urls)
+	reported := make(map[string]bool)
+	for _, image := range inventory.Images {
+		if !checkable(image.Url) {
+			image.Accessible = true
+			continue
+		}
+		result := results(image.Url)
+		image.Accessible = result.Result
+		image.LinkStatus = result.LinkStatus
+
+		key := normalizeURL(image.Url)
+		if reported[key] {
+			continue
+		}
+		reported[key] = true
+		switch {
+		case !image.Accessible:
+			issue(model.ImageBroken, image.Url, image.Selector, "the image can't be loaded")
+		case image.ContentLength > p.config.MaxImageSize:
+			issue(model.ImageOversized, image.Url, image.Selector, fmt.Sprintf("the image has %d bytes, more than %d",
+				image.ContentLength, p.config.MaxImageSize))
+		}
+	}
+	return inventory
+}
+
+// checkable reports whether the url is checked over the network, inline
+// data urls are not.
+func checkable(rawURL string) bool {
+	target, err := url.Parse(rawURL)
+	return err != nil || (target.Scheme != "data" && target.Scheme != "blob")
+}
+
+// parseSrcset splits a srcset attribute into its candidates, each a url and
+// its optional descriptor. Urls may contain commas, so a comma only ends a
+// candidate after its url.
+func parseSrcset(srcset string) [][2]string {
+	var candidates [][2]string
+	rest := srcset
+	for {
+		rest = strings.TrimLeft(rest, " \t\n\f\r,")
+		if rest == "" {
+			return candidates
+		}
+		end := strings.IndexAny(rest, " \t\n\f\r")
+		if end < 0 {
+			end = len(rest)
+		}
+		src := rest[:end]
+		rest = rest[end:]
+
+		var descriptor string
+		if trimmed := strings.TrimRight(src, ","); trimmed != src {
+			src = trimmed
+		} else {
+			end = strings.IndexByte(rest, ',')
+			if end < 0 {
+				end = len(rest)
+			}
+			descriptor = strings.TrimSpace(rest[:end])
+			rest = rest[end:]
+		}
+		candidates = append(candidates, [2]string{src, descriptor})
+	}
+}
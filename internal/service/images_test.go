@@ -0,0 +1,89 @@
+package service_test
+
+import (
+	"context"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Image inventory Test", func() {
+
+	var fetcher *servicefakes.FakeFetcher
+
+	BeforeEach(func() {
+		fetcher = &servicefakes.FakeFetcher{}
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			switch pr.Url {
+			case "https://www.example.com/img/missing.png":
+				pr.StatusCode = 404
+				pr.Failure = model.FailureClientError
+			case "https://www.example.com/img/huge.jpg":
+				pr.Result = true
+				pr.ContentLength = 5 << 20
+			default:
+				pr.Result = true
+				pr.ContentLength = 2048
+			}
+			return pr, nil
+		})
+	})
+
+	parse := func(body string) *model.ImageInventory {
+		return parsePageWith(fetcher, "https://www.example.com/docs/",
+			`<!DOCTYPE html><html><head><base href="/img/"></head><body>`+body+`</body></html>`,
+			service.ParserConfig{WorkerCount: 2}).Images
+	}
+	alt := func(text string) *string {
+		return &text
+	}
+
+	It("should collect img, srcset and picture candidates", func() {
+		images := parse(`<picture>
+				<source srcset="wide.webp 1200w, narrow.webp 600w" type="image/webp">
+				<img src="logo.png" srcset="logo@2x.png 2x,logo@3x.png 3x" alt="Logo" width="120" height="40" loading="lazy">
+			</picture>`)
+
+		const selector = "html > body > picture > img:nth-child(2)"
+		status := model.LinkStatus{ContentLength: 2048}
+		Expect(images.Images).To(Equal([]*model.Image{
+			{Source: model.ImageSourceImg, Src: "logo.png", Url: "https://www.example.com/img/logo.png", Alt: alt("Logo"),
+				Width: "120", Height: "40", Loading: "lazy", Selector: selector, Accessible: true, LinkStatus: status},
+			{Source: model.ImageSourceSrcset, Src: "logo@2x.png", Url: "https://www.example.com/img/logo@2x.png", Descriptor: "2x",
+				Alt: alt("Logo"), Width: "120", Height: "40", Loading: "lazy", Selector: selector, Accessible: true, LinkStatus: status},
+			{Source: model.ImageSourceSrcset, Src: "logo@3x.png", Url: "https://www.example.com/img/logo@3x.png", Descriptor: "3x",
+				Alt: alt("Logo"), Width: "120", Height: "40", Loading: "lazy", Selector: selector, Accessible: true, LinkStatus: status},
+			{Source: model.ImageSourcePicture, Src: "wide.webp", Url: "https://www.example.com/img/wide.webp", Descriptor: "1200w",
+				Alt: alt("Logo"), Width: "120", Height: "40", Loading: "lazy", Selector: selector, Accessible: true, LinkStatus: status},
+			{Source: model.ImageSourcePicture, Src: "narrow.webp", Url: "https://www.example.com/img/narrow.webp", Descriptor: "600w",
+				Alt: alt("Logo"), Width: "120", Height: "40", Loading: "lazy", Selector: selector, Accessible: true, LinkStatus: status},
+		}))
+		Expect(images.Issues).To(BeEmpty())
+	})
+
+	It("should check each image url once", func() {
+		parse(`<img src="a.png" alt="" width="1" height="1"><img src="a.png#x" alt="" width="1" height="1">
+			<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=" alt="" width="1" height="1">`)
+		Expect(fetcher.IsAccessibleCallCount()).To(Equal(1))
+	})
+
+	It("should report images lacking alt text or dimensions and broken or oversized ones", func() {
+		images := parse(`<img src="missing.png" alt="Chart" width="10" height="10">
+			<img src="huge.jpg" alt="">
+			<img src="icon.png" width="10" height="10">
+			<img src="spacer.png" role="presentation" width="1" height="1">`)
+
+		Expect(images.Issues).To(Equal([]*model.ImageIssue{
+			{Code: model.ImageMissingDimensions, Selector: "html > body > img:nth-child(2)",
+				Message: "the image has no width and height, the layout shifts when it loads"},
+			{Code: model.ImageMissingAlt, Selector: "html > body > img:nth-child(3)", Message: "the image has no alt attribute"},
+			{Code: model.ImageBroken, Url: "https://www.example.com/img/missing.png", Selector: "html > body > img:nth-child(1)",
+				Message: "the image can't be loaded"},
+			{Code: model.ImageOversized, Url: "https://www.example.com/img/huge.jpg", Selector: "html > body > img:nth-child(2)",
+				Message: "the image has 5242880 bytes, more than 1048576"},
+		}))
+	})
+})
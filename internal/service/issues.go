@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+)
+
+// DefaultIssueDocs is the page documenting the issue codes, each code has
+// an anchor of its own.
+const DefaultIssueDocs = "https://github.com/Dmitriy-Opria/re_web_page_analyzer/blob/master/docs/issues.md"
+
+type suppressionsContextKey struct{}
+
+// WithSuppressions returns a context whose analyses also hide the issues
+// matching the given suppressions.
+func WithSuppressions(ctx context.Context, suppressions []*model.Suppression) context.Context {
+	return context.WithValue(ctx, suppressionsContextKey{}, suppressions)
+}
+
+func requestSuppressions(ctx context.Context) []*model.Suppression {
+	suppressions, _ := ctx.Value(suppressionsContextKey{}).([]*model.Suppression)
+	return suppressions
+}
+
+// issue returns an issue of a built-in analyzer, linked to the description
+// of its code.
+func (p *ParserService) issue(analyzer, code, severity, message string) *model.Issue {
+	return &model.Issue{
+		Code:     code,
+		Severity: severity,
+		Analyzer: analyzer,
+		Message:  message,
+		DocURL:   p.config.IssueDocs + "#" + code,
+	}
+}
+
+func (p *ParserService) versionIssues(version *model.HTMLVersion) []*model.Issue {
+	if version.Mode != model.ModeQuirks {
+		return nil
+	}
+	return []*model.Issue{p.issue(AnalyzerVersion, model.IssueQuirksMode, model.SeverityMedium,
+		"the doctype makes browsers render the page in quirks mode")}
+}
+
+var metaSeverities = map[string]string{
+	model.MetaTitleMissing:       model.SeverityHigh,
+	model.MetaDescriptionMissing: model.SeverityMedium,
+	model.MetaImageRelative:      model.SeverityMedium,
+	model.MetaCanonicalRelative:  model.SeverityMedium,
+	model.MetaCanonicalOtherHost: model.SeverityMedium,
+}
+
+func (p *ParserService) metaIssues(meta *model.Metadata) []*model.Issue {
+	issues := make([]*model.Issue, 0, len(meta.Issues))
+	for _, metaIssue := range meta.Issues {
+		severity, ok := metaSeverities[metaIssue.Code]
+		if !ok {
+			severity = model.SeverityLow
+		}
+		issues = append(issues, p.issue(AnalyzerMetadata, metaIssue.Code, severity, metaIssue.Message))
+	}
+	return issues
+}
+
+func (p *ParserService) structuredDataIssues(data *model.StructuredData) []*model.Issue {
+	issues := make([]*model.Issue, 0, len(data.Issues))
+	for _, dataIssue := range data.Issues {
+		issue := p.issue(AnalyzerStructuredData, dataIssue.Code, dataIssue.Severity, dataIssue.Message)
+		if !strings.HasPrefix(dataIssue.Entity, "_:") {
+			issue.URL = dataIssue.Entity
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+var outlineSeverities = map[string]string{
+	model.HeadingH1Missing: model.SeverityMedium,
+	model.HeadingEmpty:     model.SeverityMedium,
+}
+
+func (p *ParserService) outlineIssues(outline *model.Outline) []*model.Issue {
+	issues := make([]*model.Issue, 0, len(outline.Issues))
+	for _, outlineIssue := range outline.Issues {
+		severity, ok := outlineSeverities[outlineIssue.Code]
+		if !ok {
+			severity = model.SeverityLow
+		}
+		issue := p.issue(AnalyzerHeadings, outlineIssue.Code, severity, outlineIssue.Message)
+		issue.Selector = outlineIssue.Selector
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+var linkSeverities = map[string]string{
+	model.LinkIssueJavascript: model.SeverityLow,
+}
+
+func (p *ParserService) linkIssues(links *pageLinks) []*model.Issue {
+	issues := make([]*model.Issue, 0, len(links.issues))
+	for _, linkIssue := range links.issues {
+		severity, ok := linkSeverities[linkIssue.Code]
+		if !ok {
+			severity = model.SeverityMedium
+		}
+		issue := p.issue(AnalyzerLinks, linkIssue.Code, severity, linkIssue.Message)
+		issue.URL = linkIssue.Href
+		issues = append(issues, issue)
+	}
+	// Broken internal links are the site's own, broken external ones
+	// may be temporary.
+	for _, group := range []struct {
+		severity string
+		links    []*model.Link
+	}{
+		{severity: model.SeverityHigh, links: links.internal},
+		{severity: model.SeverityMedium, links: links.external},
+	} {
+		for _, link := range group.links {
+			if link.Accessible {
+				continue
+			}
+			message := "the link target is not accessible"
+			if link.Failure != "" {
+				message = fmt.Sprintf("%s (%s)", message, link.Failure)
+			}
+			issue := p.issue(AnalyzerLinks, model.IssueBrokenLink, group.severity, message)
+			issue.URL = link.Url
+			if len(link.Positions) > 0 {
+				issue.Selector = link.Positions[0]
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+var imageSeverities = map[string]string{
+	model.ImageMissingDimensions: model.SeverityLow,
+}
+
+func (p *ParserService) imageIssues(inventory *model.ImageInventory) []*model.Issue {
+	issues := make([]*model.Issue, 0, len(inventory.Issues))
+	for _, imageIssue := range inventory.Issues {
+		severity, ok := imageSeverities[imageIssue.Code]
+		if !ok {
+			severity = model.SeverityMedium
+		}
+		issue := p.issue(AnalyzerImages, imageIssue.Code, severity, imageIssue.Message)
+		issue.Selector = imageIssue.Selector
+		issue.URL = imageIssue.Url
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func (p *ParserService) resourceIssues(resources []*model.Resource) []*model.Issue {
+	var issues []*model.Issue
+	for _, resource := range resources {
+		if resource.MixedContent != "" {
+			// Browsers block active mixed content, the page breaks.
+			severity := model.SeverityMedium
+			if resource.MixedContent == model.MixedContentActive {
+				severity = model.SeverityHigh
+			}
+			issue := p.issue(AnalyzerResources, model.IssueMixedContent, severity,
+				fmt.Sprintf("the %s is loaded over plain http (%s mixed content)", resource.Kind, resource.MixedContent))
+			issue.Selector = resource.Selector
+			issue.URL = resource.Url
+			issues = append(issues, issue)
+		}
+		if !resource.Accessible {
+			issue := p.issue(AnalyzerResources, model.IssueBrokenResource, model.SeverityMedium,
+				fmt.Sprintf("the %s is not accessible", resource.Kind))
+			issue.Selector = resource.Selector
+			issue.URL = resource.Url
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// accessibilitySeverities maps the WCAG conformance levels to severities.
+var accessibilitySeverities = map[string]string{
+	"A":  model.SeverityHigh,
+	"AA": model.SeverityMedium,
+}
+
+func (p *ParserService) accessibilityIssues(findings []*model.AccessibilityFinding) []*model.Issue {
+	issues := make([]*model.Issue, 0, len(findings))
+	for _, finding := range findings {
+		severity, ok := accessibilitySeverities[finding.Level]
+		if !ok {
+			severity = model.SeverityLow
+		}
+		issue := p.issue(AnalyzerAccessibility, finding.Rule, severity,
+			fmt.Sprintf("%s (WCAG %s)", finding.Message, finding.Criterion))
+		issue.Selector = finding.Selector
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func (p *ParserService) securityIssues(securityIssues []*model.SecurityIssue) []*model.Issue {
+	issues := make([]*model.Issue, 0, len(securityIssues))
+	for _, securityIssue := range securityIssues {
+		issue := p.issue(AnalyzerLoginSecurity, securityIssue.Code, securityIssue.Severity, securityIssue.Message)
+		issue.Selector = securityIssue.Selector
+		issue.URL = securityIssue.URL
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func (p *ParserService) i18nIssues(i18n *model.Internationalization) []*model.Issue {
+	issues := make([]*model.Issue, 0, len(i18n.Issues))
+	for _, i18nIssue := range i18n.Issues {
+		issue := p.issue(AnalyzerI18n, i18nIssue.Code, i18nIssue.Severity, i18nIssue.Message)
+		issue.Selector = i18nIssue.Selector
+		issue.URL = i18nIssue.URL
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// suppression is a compiled model.Suppression.
+type suppression struct {
+	code *regexp.Regexp
+	url  *regexp.Regexp
+}
+
+// compilePattern turns a pattern where * matches any text into a regexp.
+func compilePattern(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+	quoted := strings.Replace(regexp.QuoteMeta(pattern), `\*`, ".*", -1)
+	return regexp.MustCompile("^(?i:" + quoted + ")$")
+}
+
+func compileSuppressions(suppressions []*model.Suppression) []*suppression {
+	compiled := make([]*suppression, 0, len(suppressions))
+	for _, s := range suppressions {
+		compiled = append(compiled, &suppression{code: compilePattern(s.Code), url: compilePattern(s.URL)})
+	}
+	return compiled
+}
+
+// hides reports whether the suppression hides the issue found on the page.
+// A suppression without any pattern hides nothing.
+func (s *suppression) hides(issue *model.Issue, page string) bool {
+	if s.code == nil && s.url == nil {
+		return false
+	}
+	if s.code != nil && !s.code.MatchString(issue.Code) {
+		return false
+	}
+	return s.url == nil || s.url.MatchString(page) || (issue.URL != "" && s.url.MatchString(issue.URL))
+}
+
+// summarizeIssues drops the issues of the report hidden by the configured
+// suppressions or those of the request, from the issues and the sections of
+// the report, and counts the remaining ones. Images without alt text are
+// counted once, as the image-alt finding of the accessibility audit.
+func (p *ParserService) summarizeIssues(ctx context.Context, page string, report *model.ParserResponse) {
+	suppressions := compileSuppressions(append(append([]*model.Suppression(nil), p.config.Suppressions...), requestSuppressions(ctx)...))
+	altFindings := make(map[string]bool)
+	for _, issue := range report.Issues {
+		if issue.Analyzer == AnalyzerAccessibility && issue.Code == model.A11yImageAlt {
+			altFindings[issue.Selector] = true
+		}
+	}
+	// the issues may have been summarized before the score, those
+	// suppressed then are gone and stay counted
+	summary := &model.IssueSummary{}
+	if report.IssueSummary != nil {
+		summary.Suppressed = report.IssueSummary.Suppressed
+	}
+	issues := make([]*model.Issue, 0, len(report.Issues))
+	suppressedAlts := make(map[string]bool)
+	for _, issue := range report.Issues {
+		if issue.Analyzer == AnalyzerImages && issue.Code == model.ImageMissingAlt && altFindings[issue.Selector] {
+			continue
+		}
+		if suppressed(suppressions, issue, page) {
+			if issue.Analyzer == AnalyzerAccessibility && issue.Code == model.A11yImageAlt {
+				suppressedAlts[issue.Selector] = true
+			}
+			summary.Suppressed++
+			continue
+		}
+		switch issue.Severity {
+		case model.SeverityHigh:
+			summary.High++
+		case model.SeverityMedium:
+			summary.Medium++
+		default:
+			summary.Low++
+		}
+		issues = append(issues, issue)
+	}
+	report.Issues = issues
+	report.IssueSummary = summary
+	suppressSections(suppressions, suppressedAlts, page, report)
+}
+
+// suppressSections drops the suppressed issues from the sections of the
+// report, matched like the issues they are reported as. Images without alt
+// text are dropped with the image-alt findings of the same elements.
+func suppressSections(suppressions []*suppression, suppressedAlts map[string]bool, page string, report *model.ParserResponse) {
+	if len(suppressions) == 0 {
+		return
+	}
+	hidden := func(code, url string) bool {
+		return suppressed(suppressions, &model.Issue{Code: code, URL: url}, page)
+	}
+	if report.Metadata != nil {
+		issues := make([]*model.MetaIssue, 0, len(report.Metadata.Issues))
+		for _, issue := range report.Metadata.Issues {
+			if !hidden(issue.Code, "") {
+				issues = append(issues, issue)
+			}
+		}
+		report.Metadata.Issues = issues
+	}
+	if report.StructuredData != nil {
+		issues := make([]*model.StructuredDataIssue, 0, len(report.StructuredData.Issues))
+		for _, issue := range report.StructuredData.Issues {
+			url := issue.Entity
+			if strings.HasPrefix(url, "_:") {
+				url = ""
+			}
+			if !hidden(issue.Code, url) {
+				issues = append(issues, issue)
+			}
+		}
+		report.StructuredData.Issues = issues
+	}
+	if report.Outline != nil {
+		issues := make([]*model.OutlineIssue, 0, len(report.Outline.Issues))
+		for _, issue := range report.Outline.Issues {
+			if !hidden(issue.Code, "") {
+				issues = append(issues, issue)
+			}
+		}
+		report.Outline.Issues = issues
+	}
+	if report.LinkIssues != nil {
+		issues := make([]*model.LinkIssue, 0, len(report.LinkIssues))
+		for _, issue := range report.LinkIssues {
+			if !hidden(issue.Code, issue.Href) {
+				issues = append(issues, issue)
+			}
+		}
+		report.LinkIssues = issues
+	}
+	if report.Images != nil {
+		issues := make([]*model.ImageIssue, 0, len(report.Images.Issues))
+		for _, issue := range report.Images.Issues {
+			if issue.Code == model.ImageMissingAlt && suppressedAlts[issue.Selector] {
+				continue
+			}
+			if !hidden(issue.Code, issue.Url) {
+				issues = append(issues, issue)
+			}
+		}
+		report.Images.Issues = issues
+	}
+	if report.Accessibility != nil {
+		findings := make([]*model.AccessibilityFinding, 0, len(report.Accessibility))
+		for _, finding := range report.Accessibility {
+			if !hidden(finding.Rule, "") {
+				findings = append(findings, finding)
+			}
+		}
+		report.Accessibility = findings
+	}
+	if report.LoginSecurity != nil {
+		issues := make([]*model.SecurityIssue, 0, len(report.LoginSecurity))
+		for _, issue := range report.LoginSecurity {
+			if !hidden(issue.Code, issue.URL) {
+				issues = append(issues, issue)
+			}
+		}
+		report.LoginSecurity = issues
+	}
+	if report.I18n != nil {
+		issues := make([]*model.I18nIssue, 0, len(report.I18n.Issues))
+		for _, issue := range report.I18n.Issues {
+			if !hidden(issue.Code, issue.URL) {
+				issues = append(issues, issue)
+			}
+		}
+		report.I18n.Issues = issues
+	}
+}
+
+func suppressed(suppressions []*suppression, issue *model.Issue, page string) bool {
+	for _, s := range suppressions {
+		if s.hides(issue, page) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package service_test
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Issues Test", func() {
+
+	const page = `<!DOCTYPE html><html lang="en"><head><title>Example page title</title></head><body><main>
+		<h1>Example</h1>
+		<a href="/missing">missing</a><a href="https://other.com/">other</a>
+		<img src="/logo.png" alt="Logo" width="10" height="10">
+		</main></body></html>`
+
+	var fetcher *servicefakes.FakeFetcher
+
+	BeforeEach(func() {
+		fetcher = &servicefakes.FakeFetcher{}
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			pr.Result = pr.Url != "https://www.example.com/missing"
+			if !pr.Result {
+				pr.LinkStatus.Failure = model.FailureClientError
+			}
+			return pr, nil
+		})
+	})
+
+	issueCodes := func(issues []*model.Issue) []string {
+		codes := []string{}
+		for _, issue := range issues {
+			codes = append(codes, issue.Code)
+		}
+		return codes
+	}
+
+	It("should gather the findings of the analyzers", func() {
+		response := parsePage(fetcher, pageURL, page)
+
+		Expect(issueCodes(response.Issues)).To(Equal([]string{
+			model.MetaDescriptionMissing,
+			model.MetaImageMissing,
+			model.IssueBrokenLink,
+		}))
+		Expect(response.Issues[2]).To(Equal(&model.Issue{
+			Code:     model.IssueBrokenLink,
+			Severity: model.SeverityHigh,
+			Analyzer: service.AnalyzerLinks,
+			Message:  "the link target is not accessible (4xx)",
+			Selector: "html > body > main > a:nth-child(2)",
+			URL:      "https://www.example.com/missing",
+			DocURL:   service.DefaultIssueDocs + "#broken-link",
+		}))
+		Expect(response.IssueSummary).To(Equal(&model.IssueSummary{High: 1, Medium: 1, Low: 1}))
+	})
+
+	It("should suppress everything matching a code pattern", func() {
+		response := parsePage(fetcher, pageURL, `<html><body><img src="/a.png"></body></html>`)
+		Expect(issueCodes(response.Issues)).To(ContainElement(model.IssueQuirksMode))
+		Expect(issueCodes(response.Issues)).To(ContainElement(model.A11yImageAlt))
+
+		response = parsePageWith(fetcher, pageURL, `<html><body><img src="/a.png"></body></html>`,
+			service.ParserConfig{Suppressions: []*model.Suppression{{Code: "*"}}})
+		Expect(response.Issues).To(BeEmpty())
+		Expect(response.IssueSummary.Suppressed).To(BeNumerically(">", 5))
+		Expect(response.Metadata.Issues).To(BeEmpty())
+		Expect(response.Outline.Issues).To(BeEmpty())
+		Expect(response.Images.Issues).To(BeEmpty())
+		Expect(response.Accessibility).To(BeEmpty())
+	})
+
+	It("should report an image without alt text once", func() {
+		response := parsePage(fetcher, pageURL, `<html><body><img src="/a.png"></body></html>`)
+
+		Expect(issueCodes(response.Issues)).To(ContainElement(model.A11yImageAlt))
+		Expect(issueCodes(response.Issues)).NotTo(ContainElement(model.ImageMissingAlt))
+		Expect(response.Images.Issues[0].Code).To(Equal(model.ImageMissingAlt))
+	})
+
+	It("should suppress an image without alt text everywhere", func() {
+		response := parsePageWith(fetcher, pageURL, `<html><body><img src="/a.png"></body></html>`,
+			service.ParserConfig{Suppressions: []*model.Suppression{{Code: model.A11yImageAlt}}})
+
+		Expect(issueCodes(response.Issues)).NotTo(ContainElement(model.A11yImageAlt))
+		Expect(issueCodes(response.Issues)).NotTo(ContainElement(model.ImageMissingAlt))
+		for _, issue := range response.Images.Issues {
+			Expect(issue.Code).NotTo(Equal(model.ImageMissingAlt))
+		}
+		Expect(response.IssueSummary.Suppressed).To(Equal(1))
+	})
+
+	table.DescribeTable("should suppress the matching issues",
+		func(suppression *model.Suppression, suppressed bool) {
+			response := parsePageWith(fetcher, pageURL, page, service.ParserConfig{
+				WorkerCount:  2,
+				Suppressions: []*model.Suppression{suppression},
+			})
+
+			if suppressed {
+				Expect(issueCodes(response.Issues)).NotTo(ContainElement(model.IssueBrokenLink))
+				Expect(response.IssueSummary.Suppressed).To(Equal(1))
+				Expect(response.IssueSummary.High).To(BeZero())
+			} else {
+				Expect(issueCodes(response.Issues)).To(ContainElement(model.IssueBrokenLink))
+				Expect(response.IssueSummary.Suppressed).To(BeZero())
+			}
+		},
+		table.Entry("by code", &model.Suppression{Code: "broken-link"}, true),
+		table.Entry("by code pattern", &model.Suppression{Code: "broken-*"}, true),
+		table.Entry("by other code", &model.Suppression{Code: "broken-image"}, false),
+		table.Entry("by page url", &model.Suppression{Code: "broken-link", URL: "https://www.example.com/*"}, true),
+		table.Entry("by issue url", &model.Suppression{URL: "*/missing"}, true),
+		table.Entry("by other url", &model.Suppression{Code: "broken-link", URL: "https://other.com/*"}, false),
+		table.Entry("without pattern", &model.Suppression{Reason: "everything"}, false),
+	)
+
+	It("should apply the suppressions of the request", func() {
+		parser := service.NewParserService(fetcher, service.ParserConfig{
+			IssueDocs:    "https://docs.example.com/issues",
+			Suppressions: []*model.Suppression{{Code: model.MetaImageMissing}},
+		})
+		// parsePage stubs the fetch of the page
+		parsePage(fetcher, pageURL, page)
+
+		ctx := service.WithSuppressions(context.Background(), []*model.Suppression{{Code: model.MetaDescriptionMissing}})
+		response, err := parser.Parse(ctx, pageURL)
+		Expect(err).To(BeNil())
+
+		Expect(issueCodes(response.Issues)).To(Equal([]string{model.IssueBrokenLink}))
+		Expect(response.Issues[0].DocURL).To(Equal("https://docs.example.com/issues#broken-link"))
+		Expect(response.IssueSummary).To(Equal(&model.IssueSummary{High: 1, Suppressed: 2}))
+	})
+
+	It("should document every issue code", func() {
+		docs, err := ioutil.ReadFile("../../docs/issues.md")
+		Expect(err).To(BeNil())
+		headings := make(map[string]bool)
+		for _, line := range strings.Split(string(docs), "\n") {
+			if strings.HasPrefix(line, "### ") {
+				headings[strings.TrimSpace(strings.TrimPrefix(line, "### "))] = true
+			}
+		}
+
+		// the issue codes are the constants declared under a comment
+		// naming them codes of issues
+		packages, err := parser.ParseDir(token.NewFileSet(), "../model", nil, parser.ParseComments)
+		Expect(err).To(BeNil())
+		var codes []string
+		for _, file := range packages["model"].Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.CONST || gen.Doc == nil {
+					continue
+				}
+				doc := strings.ToLower(gen.Doc.Text())
+				if !strings.Contains(doc, "issue") || !strings.Contains(doc, "code") {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					for _, value := range spec.(*ast.ValueSpec).Values {
+						code, err := strconv.Unquote(value.(*ast.BasicLit).Value)
+						Expect(err).To(BeNil())
+						codes = append(codes, code)
+					}
+				}
+			}
+		}
+		Expect(codes).To(ContainElement(model.StructuredInvalidJSONLD))
+		for _, code := range codes {
+			Expect(headings).To(HaveKey(code), "docs/issues.md has no section for %s", code)
+		}
+	})
+})
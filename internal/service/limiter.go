@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxIdleHosts is the number of tracked hosts above which idle ones are forgotten.
+const maxIdleHosts = 1000
+
+// hostLimiter keeps requests polite towards every host: only a bounded number
+// of them run at once, consecutive ones are spaced by a minimum delay, and a
+// host which asked to back off isn't contacted again before the time it gave.
+// A single limiter is shared by all analyses, so the limits hold across them.
+type hostLimiter struct {
+	concurrency int
+	delay       time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	// slots is nil when the concurrency isn't limited.
+	slots chan struct{}
+	// next is the earliest time the next request to the host may start.
+	next time.Time
+	// users counts the requests holding the state, from acquire to release.
+	// The state is only forgotten when nobody holds it.
+	users int
+}
+
+func newHostLimiter(concurrency int, delay time.Duration) *hostLimiter {
+	return &hostLimiter{
+		concurrency: concurrency,
+		delay:       delay,
+		hosts:       make(map[string]*hostState),
+	}
+}
+
+// acquire blocks until a request to the host may start. The returned release
+// func must be called once the request is done.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	state := l.state(host)
+	state.users++
+	l.mu.Unlock()
+	release := func() {
+		l.mu.Lock()
+		state.users--
+		l.mu.Unlock()
+	}
+	if state.slots != nil {
+		select {
+		case state.slots <- struct{}{}:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+		unhold := release
+		release = func() {
+			<-state.slots
+			unhold()
+		}
+	}
+	for {
+		l.mu.Lock()
+		wait := time.Until(state.next)
+		if wait <= 0 {
+			state.next = time.Now().Add(l.delay)
+			l.mu.Unlock()
+			return release, nil
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// backoff postpones all requests to the host until the given time.
+func (l *hostLimiter) backoff(host string, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	state := l.state(host)
+	if until.After(state.next) {
+		state.next = until
+	}
+}
+
+// state returns the state of the host, created if needed. Callers must hold
+// the mutex.
+func (l *hostLimiter) state(host string) *hostState {
+	host = strings.ToLower(host)
+	if state, ok := l.hosts[host]; ok {
+		return state
+	}
+	if len(l.hosts) >= maxIdleHosts {
+		l.forgetIdle()
+	}
+	state := &hostState{}
+	if l.concurrency > 0 {
+		state.slots = make(chan struct{}, l.concurrency)
+	}
+	l.hosts[host] = state
+	return state
+}
+
+// forgetIdle drops the hosts nobody holds and without pending delays.
+// Callers must hold the mutex.
+func (l *hostLimiter) forgetIdle() {
+	now := time.Now()
+	for host, state := range l.hosts {
+		if state.users == 0 && state.next.Before(now) {
+			delete(l.hosts, host)
+		}
+	}
+}
+
+// retryAfter returns how long the server asked to wait before the next
+// request, and whether it asked at all.
+func retryAfter(response *http.Response, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(response.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			seconds = 0
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
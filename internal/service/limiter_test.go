@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Host politeness Test", func() {
+
+	var (
+		server  *httptest.Server
+		handler http.HandlerFunc
+		config  service.FetcherConfig
+		fetcher *service.FetcherService
+	)
+	BeforeEach(func() {
+		config = service.FetcherConfig{}
+	})
+	JustBeforeEach(func() {
+		server = httptest.NewServer(handler)
+		fetcher = service.NewFetcherService(server.Client(), config)
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+	checkAll := func(count int) []*model.WorkerWrapper {
+		results := make([]*model.WorkerWrapper, count)
+		var wg sync.WaitGroup
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], _ = fetcher.IsAccessible(context.Background(), &model.WorkerWrapper{Index: i, Url: server.URL})
+			}(i)
+		}
+		wg.Wait()
+		return results
+	}
+
+	Context("when the host concurrency is limited", func() {
+		var running, maxRunning int32
+		BeforeEach(func() {
+			running, maxRunning = 0, 0
+			config.HostConcurrency = 2
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				current := atomic.AddInt32(&running, 1)
+				for {
+					seen := atomic.LoadInt32(&maxRunning)
+					if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+			}
+		})
+		It("should not send more requests at once", func() {
+			for _, result := range checkAll(8) {
+				Expect(result.Result).To(BeTrue())
+			}
+			Expect(atomic.LoadInt32(&maxRunning)).To(BeEquivalentTo(2))
+		})
+	})
+
+	Context("when the host delay is set", func() {
+		BeforeEach(func() {
+			config.HostDelay = 20 * time.Millisecond
+			handler = func(w http.ResponseWriter, r *http.Request) {}
+		})
+		It("should space the requests to the host", func() {
+			start := time.Now()
+			checkAll(4)
+			Expect(time.Since(start)).To(BeNumerically(">=", 60*time.Millisecond))
+		})
+	})
+
+	Context("when the host rate limits requests", func() {
+		var calls int32
+		BeforeEach(func() {
+			calls = 0
+			config.MaxRetryAfter = 2 * time.Second
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(http.StatusTooManyRequests)
+				}
+			}
+		})
+		It("should wait for Retry-After and retry", func() {
+			start := time.Now()
+			result := checkAll(1)[0]
+			Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+			Expect(result.Result).To(BeTrue())
+			Expect(atomic.LoadInt32(&calls)).To(BeEquivalentTo(2))
+		})
+	})
+
+	Context("when the host asks to wait too long", func() {
+		BeforeEach(func() {
+			config.MaxRetryAfter = time.Second
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "120")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		})
+		It("should report the link as rate limited without waiting", func() {
+			start := time.Now()
+			result := checkAll(1)[0]
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			Expect(result.Result).To(BeFalse())
+			Expect(result.Failure).To(BeEquivalentTo(model.FailureRateLimited))
+		})
+		It("should keep away from the host until the requested time", func() {
+			checkAll(1)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			result, err := fetcher.IsAccessible(ctx, &model.WorkerWrapper{Url: server.URL})
+			Expect(err).To(BeNil())
+			Expect(result.Failure).To(BeEquivalentTo(model.FailureTimeout))
+		})
+	})
+})
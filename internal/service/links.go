@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/publicsuffix"
+)
+
+// pageLinks are the links of a document sorted into buckets. Anchors
+// pointing to the same url are aggregated into one link.
+type pageLinks struct {
+	internal []*model.Link
+	external []*model.Link
+	special  *model.SpecialLinks
+	issues   []*model.LinkIssue
+
+	seen map[string]*model.Link
+	ids  idCounts
+}
+
+func (p *ParserService) setInternalLink(ctx context.Context, doc *goquery.Document) *pageLinks {
+	links := &pageLinks{
+		special: &model.SpecialLinks{},
+		seen:    make(map[string]*model.Link),
+		ids:     countIDs(doc),
+	}
+
+	page := documentURL(doc)
+	base := documentBase(doc)
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href := strings.TrimSpace(s.AttrOr("href", ""))
+		if href == "" {
+			return
+		}
+		target, err := resolveHref(base, href)
+		// A fragment only stays on the page when no <base> points elsewhere,
+		// otherwise it is a link to the base document.
+		if strings.HasPrefix(href, "#") && (err != nil || normalizeURL(target.String()) == normalizeURL(page.String())) {
+			resolved := href
+			if err == nil {
+				resolved = target.String()
+			}
+			links.add(&links.special.Fragment, s, href, resolved)
+			return
+		}
+		if err != nil {
+			// unresolvable links are reported as invalid by the check
+			links.add(&links.external, s, href, href)
+			return
+		}
+		resolved := target.String()
+		switch strings.ToLower(target.Scheme) {
+		case "http", "https":
+			if isInternal(page, target) {
+				links.add(&links.internal, s, href, resolved)
+			} else {
+				links.add(&links.external, s, href, resolved)
+			}
+		case "mailto":
+			if link, ok := links.add(&links.special.Mailto, s, href, resolved); ok {
+				links.validate(link, validMailto(target), model.LinkIssueInvalidEmail, "mailto link has no valid email address")
+			}
+		case "tel":
+			if link, ok := links.add(&links.special.Tel, s, href, resolved); ok {
+				links.validate(link, validTel(target), model.LinkIssueInvalidPhone, "tel link is not an E.164 phone number")
+			}
+		case "ftp", "ftps", "sftp":
+			links.add(&links.special.Ftp, s, href, resolved)
+		case "data":
+			links.add(&links.special.Data, s, href, resolved)
+		case "javascript":
+			if link, ok := links.add(&links.special.Javascript, s, href, resolved); ok {
+				links.issues = append(links.issues, &model.LinkIssue{
+					Code:    model.LinkIssueJavascript,
+					Name:    link.Name,
+					Href:    link.Href,
+					Message: "javascript: url used as a link, it is not navigable without scripts",
+				})
+			}
+		default:
+			links.add(&links.special.Other, s, href, resolved)
+		}
+	})
+
+	anchors := map[string]anchorSet{normalizeURL(page.String()): documentAnchors(doc)}
+	links.validateFragments(anchors, links.special.Fragment)
+	links.validateFragments(anchors, links.internal)
+
+	p.checkLinks(ctx, append(append([]*model.Link{}, links.internal...), links.external...))
+
+	return links
+}
+
+// add records an anchor pointing to the resolved url. The first anchor of a
+// url creates its link in the bucket, the following ones are counted on it.
+// It returns the link and whether it was created.
+func (l *pageLinks) add(bucket *[]*model.Link, s *goquery.Selection, href, resolved string) (*model.Link, bool) {
+	text := linkText(s)
+	link, ok := l.seen[resolved]
+	created := !ok
+	if created {
+		link = &model.Link{
+			Name: text,
+			Href: href,
+			Url:  resolved,
+		}
+		l.seen[resolved] = link
+		*bucket = append(*bucket, link)
+	}
+	link.Occurrences++
+	if text != "" && !containsString(link.Texts, text) {
+		link.Texts = append(link.Texts, text)
+	}
+	link.Positions = append(link.Positions, selectorPath(s, l.ids))
+	return link, created
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+var schemePrefix = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*):`)
+
+// resolveHref resolves the href against the base url. Scripts and data urls
+// are often not valid urls, they are kept as they are written.
+func resolveHref(base *url.URL, href string) (*url.URL, error) {
+	target, err := base.Parse(href)
+	if err == nil {
+		return target, nil
+	}
+	if m := schemePrefix.FindStringSubmatch(href); m != nil {
+		if scheme := strings.ToLower(m[1]); scheme == "javascript" || scheme == "data" {
+			return &url.URL{Scheme: scheme, Opaque: href[len(m[0]):]}, nil
+		}
+	}
+	return nil, err
+}
+
+func (l *pageLinks) validate(link *model.Link, valid bool, code, message string) {
+	link.Valid = &valid
+	if !valid {
+		l.issues = append(l.issues, &model.LinkIssue{
+			Code:    code,
+			Name:    link.Name,
+			Href:    link.Href,
+			Message: message,
+		})
+	}
+}
+
+// anchorSet holds the fragment targets of a document.
+type anchorSet map[string]bool
+
+// documentAnchors collects the ids and <a name> values of the document.
+func documentAnchors(doc *goquery.Document) anchorSet {
+	anchors := anchorSet{}
+	doc.Find("[id]").Each(func(i int, s *goquery.Selection) {
+		anchors[s.AttrOr("id", "")] = true
+	})
+	doc.Find("a[name]").Each(func(i int, s *goquery.Selection) {
+		anchors[s.AttrOr("name", "")] = true
+	})
+	return anchors
+}
+
+// has reports whether the fragment scrolls to a place in the document. The
+// empty fragment and "top" always lead to the top of the document.
+func (a anchorSet) has(fragment string) bool {
+	return fragment == "" || strings.EqualFold(fragment, "top") || a[fragment]
+}
+
+// validateFragments checks the fragments of the links pointing into one of
+// the known documents, which are keyed by their normalized url.
+func (l *pageLinks) validateFragments(documents map[string]anchorSet, links []*model.Link) {
+	for _, link := range links {
+		target, err := url.Parse(link.Url)
+		if err != nil || !strings.Contains(link.Href, "#") {
+			continue
+		}
+		anchors, ok := documents[normalizeURL(target.String())]
+		if !ok {
+			continue
+		}
+		valid := anchors.has(target.Fragment)
+		link.FragmentValid = &valid
+		if !valid {
+			l.issues = append(l.issues, &model.LinkIssue{
+				Code:    model.LinkIssueFragment,
+				Name:    link.Name,
+				Href:    link.Href,
+				Message: fmt.Sprintf("no element with id or name %q in the target document", target.Fragment),
+			})
+		}
+	}
+}
+
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// validMailto reports whether every address of the mailto: url is a valid
+// email address.
+func validMailto(target *url.URL) bool {
+	addresses := target.Opaque
+	if addresses == "" {
+		return false
+	}
+	if unescaped, err := url.PathUnescape(addresses); err == nil {
+		addresses = unescaped
+	}
+	for _, address := range strings.Split(addresses, ",") {
+		parsed, err := mail.ParseAddress(strings.TrimSpace(address))
+		if err != nil || parsed.Name != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// validTel reports whether the tel: url holds an E.164 number. Visual
+// separators and parameters such as ;ext= are ignored.
+func validTel(target *url.URL) bool {
+	number := target.Opaque
+	if unescaped, err := url.PathUnescape(number); err == nil {
+		number = unescaped
+	}
+	if i := strings.Index(number, ";"); i >= 0 {
+		number = number[:i]
+	}
+	number = strings.NewReplacer("-", "", ".", "", " ", "", "(", "", ")", "").Replace(number)
+	return e164.MatchString(number)
+}
+
+// documentURL returns the url the document was served from.
+func documentURL(doc *goquery.Document) *url.URL {
+	if doc.Url == nil {
+		return &url.URL{}
+	}
+	return doc.Url
+}
+
+// documentBase returns the url relative references of the document resolve
+// against: the first <base href> resolved against the document url, or the
+// document url itself.
+func documentBase(doc *goquery.Document) *url.URL {
+	page := documentURL(doc)
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if base, err := page.Parse(strings.TrimSpace(href)); err == nil {
+			return base
+		}
+	}
+	return page
+}
+
+// isInternal reports whether the target belongs to the same site as the page.
+// Http and https are the same site, and so are subdomains of the same
+// registrable domain, e.g. blog.example.com and www.example.com.
+func isInternal(page, target *url.URL) bool {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return false
+	}
+	pageHost := strings.ToLower(page.Hostname())
+	targetHost := strings.ToLower(target.Hostname())
+	if pageHost == "" || targetHost == "" {
+		return false
+	}
+	if pageHost == targetHost {
+		return true
+	}
+	return registrableDomain(pageHost) == registrableDomain(targetHost)
+}
+
+// registrableDomain returns the public suffix plus one label of the host,
+// or the host itself for ip addresses and single label hosts.
+func registrableDomain(host string) string {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
+// linkText returns the anchor text with the line breaks and tabs removed.
+func linkText(s *goquery.Selection) string {
+	return strings.TrimSpace(
+		strings.Replace(
+			strings.Replace(
+				s.Text(), "\n", "", -1),
+			"\t", "", -1),
+	)
+}
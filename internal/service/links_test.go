@@ -0,0 +1,197 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// parsePage analyzes the html as if it was served from the page url. Every
+// link is accessible unless the fetcher already stubs the checks.
+func parsePage(fetcher *servicefakes.FakeFetcher, page, html string) *model.ParserResponse {
+	return parsePageWith(fetcher, page, html, service.ParserConfig{WorkerCount: 2})
+}
+
+// parsePageWith is parsePage with a custom parser configuration.
+func parsePageWith(fetcher *servicefakes.FakeFetcher, page, html string, config service.ParserConfig) *model.ParserResponse {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	Expect(err).To(BeNil())
+	doc.Url, _ = url.Parse(page)
+	fetcher.FetchReturns(&model.FetchResult{Document: doc, Meta: &model.FetchMeta{URL: page, FinalURL: page}}, nil)
+	if fetcher.IsAccessibleStub == nil {
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			pr.Result = true
+			return pr, nil
+		})
+	}
+	response, err := service.NewParserService(fetcher, config).Parse(context.Background(), page)
+	Expect(err).To(BeNil())
+	return response
+}
+
+var _ = Describe("Link resolution Test", func() {
+
+	const page = "https://www.example.com/docs/guide/index.html"
+
+	table.DescribeTable("should resolve and classify links",
+		func(head, href, expectedURL string, internal bool) {
+			response := parsePage(&servicefakes.FakeFetcher{}, page,
+				fmt.Sprintf(`<!DOCTYPE html><html><head>%s</head><body><a href="%s">link</a></body></html>`, head, href))
+
+			links := response.ExternalLinks
+			if internal {
+				links = response.InternalLinks
+			}
+			Expect(links).To(HaveLen(1))
+			Expect(links[0].Href).To(BeEquivalentTo(href))
+			Expect(links[0].Url).To(BeEquivalentTo(expectedURL))
+		},
+		table.Entry("root relative path", "", "/cert/default.asp", "https://www.example.com/cert/default.asp", true),
+		table.Entry("document relative path", "", "install.html", "https://www.example.com/docs/guide/install.html", true),
+		table.Entry("parent path", "", "../api/", "https://www.example.com/docs/api/", true),
+		table.Entry("query only", "", "?page=2", "https://www.example.com/docs/guide/index.html?page=2", true),
+		table.Entry("protocol relative", "", "//cdn.other.com/lib.js", "https://cdn.other.com/lib.js", false),
+		table.Entry("other scheme of the same host", "", "http://www.example.com/", "http://www.example.com/", true),
+		table.Entry("subdomain", "", "https://blog.example.com/post", "https://blog.example.com/post", true),
+		table.Entry("host containing the page host", "", "https://www.example.com.evil.org/", "https://www.example.com.evil.org/", false),
+		table.Entry("other site mentioning the host", "", "https://other.com/?ref=www.example.com", "https://other.com/?ref=www.example.com", false),
+		table.Entry("base href", `<base href="https://www.example.com/v2/">`, "start.html", "https://www.example.com/v2/start.html", true),
+		table.Entry("relative base href", `<base href="/v3/">`, "start.html", "https://www.example.com/v3/start.html", true),
+		table.Entry("fragment with a base href elsewhere", `<base href="/other/">`, "#top", "https://www.example.com/other/#top", true),
+		table.Entry("base href on another host", `<base href="https://static.other.com/">`, "img.png", "https://static.other.com/img.png", false),
+	)
+
+	Describe("special links", func() {
+		var (
+			fetcher  *servicefakes.FakeFetcher
+			response *model.ParserResponse
+		)
+		BeforeEach(func() {
+			fetcher = &servicefakes.FakeFetcher{}
+			response = parsePage(fetcher, page, `<!DOCTYPE html><html><body>
+				<h2 id="pricing">Pricing</h2>
+				<a href="mailto:info@example.com?subject=Hi">Mail</a>
+				<a href="mailto:not-an-address">Broken mail</a>
+				<a href="tel:+1-201-555-0123">Call</a>
+				<a href="tel:555-0123">Local call</a>
+				<a href="ftp://files.example.com/pub/">Files</a>
+				<a href="data:text/plain;base64,SGVsbG8=">Data</a>
+				<a href="#pricing">Pricing</a>
+				<a href="JavaScript: void(0)">Menu</a>
+				<a href="sms:+12015550123">Text us</a>
+			</body></html>`)
+		})
+		It("should sort them into buckets", func() {
+			special := response.SpecialLinks
+			Expect(special.Mailto).To(HaveLen(2))
+			Expect(special.Tel).To(HaveLen(2))
+			Expect(special.Ftp).To(HaveLen(1))
+			Expect(special.Data).To(HaveLen(1))
+			Expect(special.Fragment).To(HaveLen(1))
+			Expect(special.Fragment[0].Url).To(BeEquivalentTo(page + "#pricing"))
+			Expect(special.Javascript).To(HaveLen(1))
+			Expect(special.Other).To(HaveLen(1))
+			Expect(response.InternalLinks).To(BeEmpty())
+			Expect(response.ExternalLinks).To(BeEmpty())
+		})
+		It("should validate email addresses and phone numbers", func() {
+			special := response.SpecialLinks
+			Expect(*special.Mailto[0].Valid).To(BeTrue())
+			Expect(*special.Mailto[1].Valid).To(BeFalse())
+			Expect(*special.Tel[0].Valid).To(BeTrue())
+			Expect(*special.Tel[1].Valid).To(BeFalse())
+		})
+		It("should report javascript and invalid links as issues", func() {
+			var codes []string
+			for _, issue := range response.LinkIssues {
+				codes = append(codes, issue.Code)
+			}
+			Expect(codes).To(ConsistOf(model.LinkIssueInvalidEmail, model.LinkIssueInvalidPhone, model.LinkIssueJavascript))
+		})
+		It("should not check them for accessibility", func() {
+			Expect(fetcher.IsAccessibleCallCount()).To(BeZero())
+		})
+	})
+
+	Describe("fragment links", func() {
+		var response *model.ParserResponse
+		BeforeEach(func() {
+			response = parsePage(&servicefakes.FakeFetcher{}, page, `<!DOCTYPE html><html><body>
+				<h2 id="install">Install</h2>
+				<h3 id="usage">Usage</h3>
+				<a name="legacy"></a>
+				<a href="#install">Install</a>
+				<a href="#legacy">Legacy</a>
+				<a href="#pricing">Pricing</a>
+				<a href="#">Top</a>
+				<a href="/docs/guide/index.html#usage">Self usage</a>
+				<a href="index.html#missing">Self missing</a>
+				<a href="/docs/other.html#install">Other page</a>
+			</body></html>`)
+		})
+		It("should validate same page fragments", func() {
+			fragments := response.SpecialLinks.Fragment
+			Expect(fragments).To(HaveLen(4))
+			Expect(*fragments[0].FragmentValid).To(BeTrue())
+			Expect(*fragments[1].FragmentValid).To(BeTrue())
+			Expect(*fragments[2].FragmentValid).To(BeFalse())
+			Expect(*fragments[3].FragmentValid).To(BeTrue())
+		})
+		It("should validate fragments of internal links into the analyzed document", func() {
+			internal := response.InternalLinks
+			Expect(internal).To(HaveLen(3))
+			Expect(*internal[0].FragmentValid).To(BeTrue())
+			Expect(*internal[1].FragmentValid).To(BeFalse())
+			Expect(internal[2].FragmentValid).To(BeNil())
+		})
+		It("should report dead anchors as issues", func() {
+			var hrefs []string
+			for _, issue := range response.LinkIssues {
+				Expect(issue.Code).To(BeEquivalentTo(model.LinkIssueFragment))
+				hrefs = append(hrefs, issue.Href)
+			}
+			Expect(hrefs).To(ConsistOf("#pricing", "index.html#missing"))
+		})
+	})
+
+	Describe("repeated links", func() {
+		var (
+			fetcher  *servicefakes.FakeFetcher
+			response *model.ParserResponse
+		)
+		BeforeEach(func() {
+			fetcher = &servicefakes.FakeFetcher{}
+			response = parsePage(fetcher, page, `<!DOCTYPE html><html><body>
+				<header id="top-nav"><a href="/pricing">Pricing</a><a href="/docs/">Docs</a></header>
+				<main><a href="https://www.example.com/pricing">See our plans</a></main>
+				<footer><a href="/pricing">Pricing</a><a href="/pricing#faq">Pricing FAQ</a></footer>
+			</body></html>`)
+		})
+		It("should aggregate them by resolved url", func() {
+			Expect(response.InternalLinks).To(HaveLen(3))
+			pricing := response.InternalLinks[0]
+			Expect(pricing.Url).To(BeEquivalentTo("https://www.example.com/pricing"))
+			Expect(pricing.Name).To(BeEquivalentTo("Pricing"))
+			Expect(pricing.Occurrences).To(BeEquivalentTo(3))
+			Expect(pricing.Texts).To(BeEquivalentTo([]string{"Pricing", "See our plans"}))
+			Expect(pricing.Positions).To(BeEquivalentTo([]string{
+				"header#top-nav > a:nth-child(1)",
+				"html > body > main:nth-child(2) > a",
+				"html > body > footer:nth-child(3) > a:nth-child(1)",
+			}))
+		})
+		It("should check every url once", func() {
+			Expect(fetcher.IsAccessibleCallCount()).To(BeEquivalentTo(2))
+			Expect(response.InternalLinks[2].Accessible).To(BeTrue())
+		})
+	})
+})
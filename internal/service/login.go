@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultLoginThreshold is the score from which a form is a login form.
+const DefaultLoginThreshold = 0.5
+
+// Weights of the signals scored by the login detector. Negative weights are
+// signals of other kinds of forms.
+const (
+	weightPassword        = 0.5
+	weightCurrentPassword = 0.3
+	weightNewPassword     = -0.3
+	weightUsername        = 0.2
+	weightFewFields       = 0.1
+	weightManyFields      = -0.3
+	maxKeywordWeight      = 0.3
+	weightSearch          = -0.5
+)
+
+// KeywordSource provides the login keyword dictionaries. They may change
+// while the service runs, so they are looked up for every analysis.
+type KeywordSource interface {
+	Keywords() *model.KeywordDictionary
+}
+
+type staticKeywords struct {
+	dictionary *model.KeywordDictionary
+}
+
+func (s staticKeywords) Keywords() *model.KeywordDictionary {
+	return s.dictionary
+}
+
+var (
+	usernameField = regexp.MustCompile(`(?i)user|login|email|e-mail|account`)
+	searchField   = regexp.MustCompile(`(?i)^(q|query|search|s|keywords?)$`)
+)
+
+// loginScore accumulates the fired signals of one form.
+type loginScore struct {
+	score   float64
+	signals []string
+}
+
+func (l *loginScore) add(weight float64, signal string) {
+	l.score += weight
+	l.signals = append(l.signals, signal)
+}
+
+// documentLanguage returns the language declared on the root element.
+func documentLanguage(doc *goquery.Document) string {
+	root := doc.Find("html").First()
+	if lang := strings.TrimSpace(root.AttrOr("lang", "")); lang != "" {
+		return lang
+	}
+	return strings.TrimSpace(root.AttrOr("xml:lang", ""))
+}
+
+// scoreLoginForm scores how likely the scanned form is a login form.
+func scoreLoginForm(scan *formScan, form *goquery.Selection, threshold float64, keywords *model.LanguageKeywords) *model.LoginDetection {
+	score := &loginScore{}
+
+	var visibleFields int
+	for _, input := range scan.inputs {
+		if !visibleField(input) {
+			continue
+		}
+		visibleFields++
+
+		switch {
+		case input.Type == "password":
+			score.add(weightPassword, "password-field")
+		case input.Type == "search" || searchField.MatchString(input.Name):
+			score.add(weightSearch, "search-field")
+		case input.Type == "email" || input.Autocomplete == "username" || input.Autocomplete == "email" ||
+			usernameField.MatchString(input.Name+" "+input.ID):
+			score.add(weightUsername, "username-field")
+		}
+		switch input.Autocomplete {
+		case "current-password":
+			score.add(weightCurrentPassword, "autocomplete=current-password")
+		case "new-password":
+			score.add(weightNewPassword, "autocomplete=new-password")
+		}
+	}
+	if strings.EqualFold(form.AttrOr("role", ""), "search") {
+		score.add(weightSearch, "role=search")
+	}
+
+	switch {
+	case visibleFields >= 2 && visibleFields <= 4:
+		score.add(weightFewFields, fmt.Sprintf("field-count:%d", visibleFields))
+	case visibleFields >= 5:
+		score.add(weightManyFields, fmt.Sprintf("field-count:%d", visibleFields))
+	}
+
+	for _, text := range scan.submits {
+		if phrase, ok := keywords.MatchSubmit(text); ok {
+			score.add(phrase.Weight, "submit-text:"+phrase.Term)
+			break
+		}
+	}
+
+	var keywordWeight float64
+	for _, text := range scan.texts {
+		for _, keyword := range keywords.MatchKeywords(text) {
+			if keywordWeight+keyword.Weight > maxKeywordWeight+1e-9 {
+				continue
+			}
+			keywordWeight += keyword.Weight
+			score.add(keyword.Weight, "keyword:"+keyword.Term)
+		}
+	}
+
+	value := math.Round(math.Max(0, math.Min(1, score.score))*100) / 100
+	return &model.LoginDetection{
+		Score:     value,
+		Threshold: threshold,
+		IsLogin:   value >= threshold,
+		Signals:   append([]string{}, score.signals...),
+	}
+}
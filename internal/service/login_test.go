@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"fmt"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Login detection Test", func() {
+
+	table.DescribeTable("should score forms",
+		func(form string, login bool, signals ...string) {
+			response := parsePage(&servicefakes.FakeFetcher{}, pageURL,
+				fmt.Sprintf(`<!DOCTYPE html><html><body>%s</body></html>`, form))
+
+			Expect(response.Login).To(BeEquivalentTo(login))
+			Expect(response.LoginDetection.Threshold).To(BeEquivalentTo(0.5))
+			for _, signal := range signals {
+				Expect(response.LoginDetection.Signals).To(ContainElement(signal))
+			}
+		},
+		table.Entry("password form without visible text", `<form>
+				<input type="email" autocomplete="username">
+				<input type="password" autocomplete="current-password">
+				<button></button>
+			</form>`, true, "password-field", "autocomplete=current-password", "username-field", "field-count:2"),
+		table.Entry("labelled form without field types", `<form>
+				<label>Username</label><input>
+				<label>Password</label><input>
+				<button>Sign In</button>
+			</form>`, true, "submit-text:sign in", "keyword:username"),
+		table.Entry("newsletter form", `<form>
+				<label>Your email</label>
+				<input type="email" name="email">
+				<button>Sign up</button>
+			</form>`, false, "username-field"),
+		table.Entry("search form", `<form role="search" action="/search">
+				<input type="search" name="q" placeholder="Search by name">
+				<button>Search</button>
+			</form>`, false, "search-field", "role=search"),
+		table.Entry("signup form", `<form>
+				<input name="first_name"><input name="last_name"><input type="email" name="email">
+				<input type="tel" name="phone"><input type="password" autocomplete="new-password">
+				<input type="password" autocomplete="new-password">
+				<button>Create account</button>
+			</form>`, false, "autocomplete=new-password", "field-count:6"),
+		table.Entry("form with five fields", `<form>
+				<input name="first_name"><input name="last_name"><input type="email" name="email">
+				<input type="tel" name="phone"><input type="password" autocomplete="new-password">
+				<button>Register</button>
+			</form>`, false, "field-count:5"),
+	)
+
+	Context("with keyword dictionaries", func() {
+		dictionary := &model.KeywordDictionary{
+			Default: "en",
+			Languages: map[string]*model.LanguageKeywords{
+				"en": model.DefaultKeywords().Languages["en"],
+				"de": {
+					Keywords: []model.Keyword{{Term: "benutzername", Weight: 0.1}, {Term: "passwort", Weight: 0.1}},
+					Submit:   []model.Keyword{{Term: "anmelden", Weight: 0.3}},
+				},
+			},
+		}
+		form := `<form>
+				<label>Benutzername</label><input>
+				<label>Passwort</label><input>
+				<button>Anmelden</button>
+			</form>`
+
+		detect := func(lang string) *model.LoginDetection {
+			return parsePageWith(&servicefakes.FakeFetcher{}, pageURL,
+				fmt.Sprintf(`<!DOCTYPE html><html lang="%s"><body>%s</body></html>`, lang, form),
+				service.ParserConfig{Keywords: keywordSource{dictionary}}).LoginDetection
+		}
+
+		It("should use the dictionary of the page language", func() {
+			detection := detect("de-AT")
+			Expect(detection.IsLogin).To(BeTrue())
+			Expect(detection.Language).To(Equal("de-AT"))
+			Expect(detection.Signals).To(ContainElement("submit-text:anmelden"))
+			Expect(detection.Signals).To(ContainElement("keyword:passwort"))
+		})
+
+		It("should fall back to the default language", func() {
+			detection := detect("fr")
+			Expect(detection.IsLogin).To(BeFalse())
+			Expect(detection.Signals).NotTo(ContainElement("submit-text:anmelden"))
+		})
+	})
+})
+
+type keywordSource struct {
+	dictionary *model.KeywordDictionary
+}
+
+func (s keywordSource) Keywords() *model.KeywordDictionary {
+	return s.dictionary
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Length limits, in characters, search engines show titles and descriptions
+// within.
+const (
+	minTitleLength       = 10
+	maxTitleLength       = 60
+	minDescriptionLength = 50
+	maxDescriptionLength = 160
+)
+
+// metadata collects the meta tags of the document and validates them.
+func (p *ParserService) metadata(doc *goquery.Document) *model.Metadata {
+	meta := &model.Metadata{
+		Title:     normalizeSpace(doc.Find("title").First().Text()),
+		OpenGraph: make(map[string]string),
+		Twitter:   make(map[string]string),
+		Issues:    make([]*model.MetaIssue, 0),
+	}
+
+	doc.Find("meta").Each(func(i int, s *goquery.Selection) {
+		content := strings.TrimSpace(s.AttrOr("content", ""))
+		if charset, ok := s.Attr("charset"); ok && meta.Charset == "" {
+			meta.Charset = strings.ToLower(strings.TrimSpace(charset))
+		}
+		if strings.EqualFold(s.AttrOr("http-equiv", ""), "content-type") && meta.Charset == "" {
+			if _, params, err := mime.ParseMediaType(content); err == nil {
+				meta.Charset = strings.ToLower(params["charset"])
+			}
+		}
+
+		name := strings.ToLower(strings.TrimSpace(s.AttrOr("name", "")))
+		property := strings.ToLower(strings.TrimSpace(s.AttrOr("property", name)))
+		switch {
+		case name == "description":
+			setOnce(&meta.Description, content)
+		case name == "robots":
+			setOnce(&meta.Robots, content)
+		case name == "viewport":
+			setOnce(&meta.Viewport, content)
+		case strings.HasPrefix(property, "og:"):
+			if _, ok := meta.OpenGraph[property]; !ok {
+				meta.OpenGraph[property] = content
+			}
+		case strings.HasPrefix(property, "twitter:"):
+			if _, ok := meta.Twitter[property]; !ok {
+				meta.Twitter[property] = content
+			}
+		}
+	})
+
+	doc.Find("link[rel][href]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		for _, rel := range strings.Fields(strings.ToLower(s.AttrOr("rel", ""))) {
+			if rel == "canonical" {
+				meta.Canonical = strings.TrimSpace(s.AttrOr("href", ""))
+				return false
+			}
+		}
+		return true
+	})
+
+	if meta.Canonical != "" {
+		if canonical, err := documentBase(doc).Parse(meta.Canonical); err == nil {
+			meta.CanonicalURL = canonical.String()
+		}
+	}
+
+	meta.Card = socialCard(meta, documentBase(doc))
+	validateMetadata(meta, documentURL(doc))
+	return meta
+}
+
+func setOnce(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// socialCard builds the card from the OpenGraph properties, falling back to
+// the Twitter ones and then to the plain title and description. The image and
+// url are resolved like links.
+func socialCard(meta *model.Metadata, base *url.URL) *model.SocialCard {
+	first := func(values ...string) string {
+		for _, value := range values {
+			if value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	resolve := func(href string) string {
+		if target, err := resolveHref(base, href); err == nil && href != "" {
+			return target.String()
+		}
+		return href
+	}
+	og, twitter := meta.OpenGraph, meta.Twitter
+	return &model.SocialCard{
+		Type:        first(og["og:type"], twitter["twitter:card"]),
+		Title:       first(og["og:title"], twitter["twitter:title"], meta.Title),
+		Description: first(og["og:description"], twitter["twitter:description"], meta.Description),
+		Image:       resolve(first(og["og:image"], og["og:image:url"], twitter["twitter:image"], twitter["twitter:image:src"])),
+		URL:         resolve(first(og["og:url"], meta.CanonicalURL)),
+		SiteName:    first(og["og:site_name"], twitter["twitter:site"]),
+	}
+}
+
+func validateMetadata(meta *model.Metadata, page *url.URL) {
+	issue := func(code, field, message string) {
+		meta.Issues = append(meta.Issues, &model.MetaIssue{Code: code, Field: field, Message: message})
+	}
+	length := func(field, value string, min, max int, missing, tooShort, tooLong string) {
+		switch n := utf8.RuneCountInString(value); {
+		case n == 0:
+			issue(missing, field, fmt.Sprintf("the page has no %s", field))
+		case n < min:
+			issue(tooShort, field, fmt.Sprintf("the %s has %d characters, less than %d", field, n, min))
+		case n > max:
+			issue(tooLong, field, fmt.Sprintf("the %s has %d characters, more than %d", field, n, max))
+		}
+	}
+	length("title", meta.Title, minTitleLength, maxTitleLength,
+		model.MetaTitleMissing, model.MetaTitleTooShort, model.MetaTitleTooLong)
+	length("description", meta.Description, minDescriptionLength, maxDescriptionLength,
+		model.MetaDescriptionMissing, model.MetaDescriptionTooShort, model.MetaDescriptionTooLong)
+
+	switch image, imageURL := meta.OpenGraph["og:image"], meta.OpenGraph["og:image:url"]; {
+	case image == "" && imageURL == "":
+		issue(model.MetaImageMissing, "og:image", "the page has no og:image, shared links show no picture")
+	case relativeURL(image) || relativeURL(imageURL):
+		issue(model.MetaImageRelative, "og:image", "the og:image url is relative, OpenGraph requires an absolute url")
+	}
+
+	if meta.Canonical == "" {
+		return
+	}
+	if relativeURL(meta.Canonical) {
+		issue(model.MetaCanonicalRelative, "canonical", "the canonical url is relative")
+	}
+	if canonical, err := url.Parse(meta.CanonicalURL); err == nil && meta.CanonicalURL != "" && !strings.EqualFold(canonical.Hostname(), page.Hostname()) {
+		issue(model.MetaCanonicalOtherHost, "canonical",
+			fmt.Sprintf("the canonical url points to %s instead of %s", canonical.Hostname(), page.Hostname()))
+	}
+}
+
+// relativeURL reports whether the value is a relative url.
+func relativeURL(value string) bool {
+	target, err := url.Parse(value)
+	return value != "" && err == nil && !target.IsAbs()
+}
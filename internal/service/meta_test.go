@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Metadata Test", func() {
+
+	const description = "A description long enough to be shown by search engines in results."
+
+	It("should extract the meta tags and the social card", func() {
+		response := parsePage(&servicefakes.FakeFetcher{}, "https://www.example.com/blog/post",
+			`<!DOCTYPE html><html><head>
+			<meta charset="UTF-8">
+			<title>  Writing a   page analyzer </title>
+			<meta name="description" content="`+description+`">
+			<meta name="Robots" content="index, follow">
+			<meta name="viewport" content="width=device-width, initial-scale=1">
+			<link rel="alternate canonical" href="https://www.example.com/blog/post">
+			<meta property="og:title" content="Analyzer">
+			<meta property="og:type" content="article">
+			<meta property="og:image" content="https://cdn.example.com/card.png">
+			<meta property="og:image" content="https://cdn.example.com/other.png">
+			<meta name="twitter:card" content="summary_large_image">
+			<meta name="twitter:site" content="@example">
+			</head><body></body></html>`)
+
+		meta := response.Metadata
+		Expect(meta.Title).To(Equal("Writing a page analyzer"))
+		Expect(meta.Description).To(Equal(description))
+		Expect(meta.Robots).To(Equal("index, follow"))
+		Expect(meta.Viewport).To(Equal("width=device-width, initial-scale=1"))
+		Expect(meta.Charset).To(Equal("utf-8"))
+		Expect(meta.CanonicalURL).To(Equal("https://www.example.com/blog/post"))
+		Expect(meta.OpenGraph).To(Equal(map[string]string{
+			"og:title": "Analyzer",
+			"og:type":  "article",
+			"og:image": "https://cdn.example.com/card.png",
+		}))
+		Expect(meta.Twitter).To(Equal(map[string]string{
+			"twitter:card": "summary_large_image",
+			"twitter:site": "@example",
+		}))
+		Expect(meta.Card).To(Equal(&model.SocialCard{
+			Type:        "article",
+			Title:       "Analyzer",
+			Description: description,
+			Image:       "https://cdn.example.com/card.png",
+			URL:         "https://www.example.com/blog/post",
+			SiteName:    "@example",
+		}))
+		Expect(meta.Issues).To(BeEmpty())
+	})
+
+	It("should resolve the urls of the social card", func() {
+		response := parsePage(&servicefakes.FakeFetcher{}, "https://www.example.com/blog/post",
+			`<!DOCTYPE html><html><head>
+			<base href="/static/">
+			<meta property="og:url" content="../blog/post">
+			<meta name="twitter:image" content="card.png">
+			</head><body></body></html>`)
+
+		card := response.Metadata.Card
+		Expect(card.Image).To(Equal("https://www.example.com/static/card.png"))
+		Expect(card.URL).To(Equal("https://www.example.com/blog/post"))
+	})
+
+	It("should read the charset of the content type", func() {
+		response := parsePage(&servicefakes.FakeFetcher{}, pageURL,
+			`<!DOCTYPE html><html><head><meta http-equiv="Content-Type" content="text/html; charset=ISO-8859-1"></head></html>`)
+		Expect(response.Metadata.Charset).To(Equal("iso-8859-1"))
+	})
+
+	table.DescribeTable("should validate the meta tags",
+		func(head string, codes ...string) {
+			response := parsePage(&servicefakes.FakeFetcher{}, "https://www.example.com/page",
+				fmt.Sprintf(`<!DOCTYPE html><html><head>%s</head><body></body></html>`, head))
+
+			var found []string
+			for _, issue := range response.Metadata.Issues {
+				found = append(found, issue.Code)
+			}
+			Expect(found).To(Equal(codes))
+		},
+		table.Entry("empty head", ``,
+			model.MetaTitleMissing, model.MetaDescriptionMissing, model.MetaImageMissing),
+		table.Entry("short texts", `<title>Home</title><meta name="description" content="Welcome">
+				<meta property="og:image" content="https://www.example.com/card.png">`,
+			model.MetaTitleTooShort, model.MetaDescriptionTooShort),
+		table.Entry("long texts", `<title>`+strings.Repeat("Ünïcödé ", 8)+`</title>
+				<meta name="description" content="`+strings.Repeat("description ", 14)+`">
+				<meta property="og:image" content="https://www.example.com/card.png">`,
+			model.MetaTitleTooLong, model.MetaDescriptionTooLong),
+		table.Entry("relative og:image", `<title>A page with a title</title>
+				<meta name="description" content="`+description+`">
+				<meta property="og:image" content="/card.png">`,
+			model.MetaImageRelative),
+		table.Entry("relative canonical", `<title>A page with a title</title>
+				<meta name="description" content="`+description+`">
+				<meta property="og:image:url" content="https://www.example.com/card.png"><link rel="canonical" href="/page">`,
+			model.MetaCanonicalRelative),
+		table.Entry("canonical on another host", `<title>A page with a title</title>
+				<meta name="description" content="`+description+`">
+				<meta property="og:image" content="https://www.example.com/card.png"><link rel="canonical" href="https://mirror.example.org/page">`,
+			model.MetaCanonicalOtherHost),
+	)
+})
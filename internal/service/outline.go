@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// pageHeadings holds the outline of a document together with the texts of
+// its headings by level, which the flat ListH1..ListH6 fields expose.
+type pageHeadings struct {
+	outline *model.Outline
+	texts   [7][]string
+}
+
+// headings builds the outline of the document in one pass over its headings.
+func (p *ParserService) headings(doc *goquery.Document) *pageHeadings {
+	h := &pageHeadings{outline: &model.Outline{
+		Headings: make([]*model.Heading, 0),
+		Issues:   make([]*model.OutlineIssue, 0),
+	}}
+	issue := func(code, selector, message string) {
+		h.outline.Issues = append(h.outline.Issues, &model.OutlineIssue{Code: code, Selector: selector, Message: message})
+	}
+
+	ids := countIDs(doc)
+	var open []*model.Heading
+	var h1s []*model.Heading
+	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(i int, s *goquery.Selection) {
+		heading := &model.Heading{
+			Level:    int(goquery.NodeName(s)[1] - '0'),
+			Text:     visibleText(s),
+			Selector: selectorPath(s, ids),
+			Hidden:   ariaHidden(s),
+		}
+		h.texts[heading.Level] = append(h.texts[heading.Level], normalizeSpace(s.Text()))
+
+		if len(open) > 0 {
+			if previous := open[len(open)-1]; heading.Level > previous.Level+1 {
+				issue(model.HeadingSkippedLevel, heading.Selector, fmt.Sprintf("h%d follows h%d, skipping h%d",
+					heading.Level, previous.Level, previous.Level+1))
+			}
+		}
+		if heading.Text == "" {
+			issue(model.HeadingEmpty, heading.Selector, fmt.Sprintf("the h%d has no text", heading.Level))
+		}
+		if heading.Hidden {
+			issue(model.HeadingHidden, heading.Selector, fmt.Sprintf("the h%d is hidden with aria-hidden", heading.Level))
+		}
+		if heading.Level == 1 {
+			h1s = append(h1s, heading)
+		}
+
+		for len(open) > 0 && open[len(open)-1].Level >= heading.Level {
+			open = open[:len(open)-1]
+		}
+		if len(open) == 0 {
+			h.outline.Headings = append(h.outline.Headings, heading)
+		} else {
+			parent := open[len(open)-1]
+			parent.Children = append(parent.Children, heading)
+		}
+		open = append(open, heading)
+	})
+
+	switch {
+	case len(h1s) == 0:
+		issue(model.HeadingH1Missing, "", "the page has no h1")
+	case len(h1s) > 1:
+		for _, heading := range h1s[1:] {
+			issue(model.HeadingH1Multiple, heading.Selector, fmt.Sprintf("the page has %d h1 headings", len(h1s)))
+		}
+	}
+	return h
+}
+
+// visibleText is the text of the element, or the alternative text of its
+// images for elements made of images.
+func visibleText(s *goquery.Selection) string {
+	if text := normalizeSpace(s.Text()); text != "" {
+		return text
+	}
+	var alts []string
+	s.Find("img[alt]").Each(func(i int, img *goquery.Selection) {
+		if alt := normalizeSpace(img.AttrOr("alt", "")); alt != "" {
+			alts = append(alts, alt)
+		}
+	})
+	return strings.Join(alts, " ")
+}
+
+// ariaHidden reports whether the element or one of its ancestors is hidden
+// from assistive technologies.
+func ariaHidden(s *goquery.Selection) bool {
+	for node := s; node.Length() > 0; node = node.Parent() {
+		if strings.EqualFold(strings.TrimSpace(node.AttrOr("aria-hidden", "")), "true") {
+			return true
+		}
+	}
+	return false
+}
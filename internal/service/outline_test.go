@@ -0,0 +1,63 @@
+package service_test
+
+import (
+	"fmt"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Heading outline Test", func() {
+
+	It("should build the outline in document order", func() {
+		response := parsePage(&servicefakes.FakeFetcher{}, pageURL, `<!DOCTYPE html><html><body>
+			<h1>Guide</h1>
+			<h2>Install</h2>
+			<h3>Linux</h3>
+			<h2>Usage</h2>
+			<div><h3>  Flags </h3></div>
+			<h2><img src="faq.png" alt="FAQ"></h2>
+			</body></html>`)
+
+		Expect(response.Outline.Headings).To(Equal([]*model.Heading{
+			{Level: 1, Text: "Guide", Selector: "html > body > h1:nth-child(1)", Children: []*model.Heading{
+				{Level: 2, Text: "Install", Selector: "html > body > h2:nth-child(2)", Children: []*model.Heading{
+					{Level: 3, Text: "Linux", Selector: "html > body > h3:nth-child(3)"},
+				}},
+				{Level: 2, Text: "Usage", Selector: "html > body > h2:nth-child(4)", Children: []*model.Heading{
+					{Level: 3, Text: "Flags", Selector: "html > body > div:nth-child(5) > h3"},
+				}},
+				{Level: 2, Text: "FAQ", Selector: "html > body > h2:nth-child(6)"},
+			}},
+		}))
+		Expect(response.Outline.Issues).To(BeEmpty())
+		Expect(response.ListH2).To(Equal([]string{"Install", "Usage", ""}))
+		Expect(response.ListH3).To(Equal([]string{"Linux", "Flags"}))
+		Expect(response.ListH4).To(BeNil())
+	})
+
+	table.DescribeTable("should report hierarchy violations",
+		func(body string, codes ...string) {
+			response := parsePage(&servicefakes.FakeFetcher{}, pageURL,
+				fmt.Sprintf(`<!DOCTYPE html><html><body>%s</body></html>`, body))
+
+			var found []string
+			for _, issue := range response.Outline.Issues {
+				found = append(found, issue.Code)
+			}
+			Expect(found).To(Equal(codes))
+		},
+		table.Entry("no headings", ``, model.HeadingH1Missing),
+		table.Entry("missing h1", `<h2>Intro</h2>`, model.HeadingH1Missing),
+		table.Entry("multiple h1", `<h1>One</h1><h1>Two</h1><h1>Three</h1>`,
+			model.HeadingH1Multiple, model.HeadingH1Multiple),
+		table.Entry("skipped level", `<h1>Page</h1><h2>Section</h2><h4>Detail</h4><h2>Next</h2>`,
+			model.HeadingSkippedLevel),
+		table.Entry("empty heading", `<h1>Page</h1><h2> </h2>`, model.HeadingEmpty),
+		table.Entry("hidden heading", `<h1>Page</h1><div aria-hidden="true"><h2>Menu</h2></div>`,
+			model.HeadingHidden),
+	)
+})
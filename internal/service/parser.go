@@ -2,204 +2,144 @@ package service
 
 import (
 	"context"
-	"fmt"
-	"strings"
-	"sync"
 	"time"
 
-	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/log"
 	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
 	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
 )
 
 type ParserRepository interface {
 	Parse(ctx context.Context) error
 }
 
-type ParserService struct {
-	fetcher     Fetcher
-	workerCount int
+// ParserConfig holds the tunables of the parser service.
+type ParserConfig struct {
+	// WorkerCount is the number of links checked in parallel for one request.
+	WorkerCount int
+	// LinkCheckTimeout bounds the time spent checking all links of a page,
+	// and the other urls its analyzers check or fetch, from when the page is
+	// fetched. Zero means no deadline besides the request context.
+	LinkCheckTimeout time.Duration
+	// LoginThreshold is the score from which a form is a login form,
+	// DefaultLoginThreshold when zero.
+	LoginThreshold float64
+	// Keywords provides the login keyword dictionaries, the built-in
+	// English one when nil.
+	Keywords KeywordSource
+	// MaxImageSize is the size in bytes from which an image is oversized,
+	// DefaultMaxImageSize when zero.
+	MaxImageSize int64
+	// IssueDocs is the page documenting the issue codes, DefaultIssueDocs
+	// when empty.
+	IssueDocs string
+	// Suppressions hide the accepted issues of every analysis.
+	Suppressions []*model.Suppression
+	// FetchAlternates allows fetching the hreflang alternates of the page
+	// to check that they link back to it, requests may allow it with
+	// WithAlternates.
+	FetchAlternates bool
+	// Scoring holds the rules the page is rated with,
+	// model.DefaultScoringRules when nil.
+	Scoring *model.ScoringRules
+}
 
-	sync.WaitGroup
+type ParserService struct {
+	fetcher   Fetcher
+	config    ParserConfig
+	analyzers *AnalyzerRegistry
 }
 
-func NewParserService(fetcher Fetcher, workerCount int) *ParserService {
-	return &ParserService{
-		fetcher:     fetcher,
-		workerCount: workerCount,
+func NewParserService(fetcher Fetcher, config ParserConfig) *ParserService {
+	if config.WorkerCount <= 0 {
+		config.WorkerCount = 1
+	}
+	if config.LoginThreshold <= 0 {
+		config.LoginThreshold = DefaultLoginThreshold
+	}
+	if config.MaxImageSize <= 0 {
+		config.MaxImageSize = DefaultMaxImageSize
+	}
+	if config.IssueDocs == "" {
+		config.IssueDocs = DefaultIssueDocs
 	}
+	if config.Scoring == nil {
+		config.Scoring = model.DefaultScoringRules()
+	}
+	if config.Keywords == nil {
+		config.Keywords = staticKeywords{dictionary: model.DefaultKeywords()}
+	}
+	p := &ParserService{
+		fetcher:   fetcher,
+		config:    config,
+		analyzers: NewAnalyzerRegistry(),
+	}
+	p.registerBuiltins()
+	return p
 }
 
+// Parse fetches the page and runs the analyzers selected with WithAnalyzers,
+// all registered analyzers when none are. Analyzers add their findings to
+// the issues of the report, which are then filtered by the suppressions,
+// before the score and once all analyzers ran.
 func (p *ParserService) Parse(ctx context.Context, url string) (*model.ParserResponse, error) {
-	// Load the HTML document
-	doc, err := p.fetcher.Fetch(url)
+	analyzers, err := p.analyzers.Select(selectedAnalyzers(ctx))
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	internalLink, externalLink := p.setInternalLink(ctx, doc)
-	return &model.ParserResponse{
-		Version:       p.version(doc),
-		Title:         p.title(doc),
-		ListH1:        p.header(doc, 1),
-		ListH2:        p.header(doc, 2),
-		ListH3:        p.header(doc, 3),
-		ListH4:        p.header(doc, 4),
-		ListH5:        p.header(doc, 5),
-		ListH6:        p.header(doc, 6),
-		InternalLinks: internalLink,
-		ExternalLinks: externalLink,
-		Login:         p.login(doc),
-	}, nil
-}
-
-func (p *ParserService) version(doc *goquery.Document) string {
-	body, err := doc.Html()
+	// Load the HTML document
+	result, err := p.fetcher.Fetch(ctx, url)
 	if err != nil {
-		return "undefined"
-	}
-	var versionHTML string
-	if len(body) > strings.Index(body, "<!") && len(body) > strings.Index(body, ">")+1 {
-		versionHTML = body[strings.Index(body, "<!") : strings.Index(body, ">")+1]
+		return nil, err
 	}
 
-	switch {
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD HTML 4.01 Frameset//EN")):
-		return "HTML 4.01 Frameset"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD HTML 4.01 Transitional//EN")):
-		return "HTML 4.01 Transitional"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD HTML 4.01//EN")):
-		return "HTML 4.01 Strict"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD XHTML 1.0 Frameset//EN")):
-		return "XHTML 1.0 Frameset"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD XHTML 1.0 Transitional//EN")):
-		return "XHTML 1.0 Transitional"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD XHTML 1.0 Strict//EN")):
-		return "XHTML 1.0 Strict"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD XHTML 1.1//EN")):
-		return "XHTML 1.1 DTD"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD XHTML Basic 1.1//EN")):
-		return "XHTML Basic 1.1"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD XHTML 1.1 plus MathML 2.0 plus SVG 1.1//EN")):
-		return "XHTML + MathML + SVG - DTD"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD XHTML 1.1 plus MathML 2.0 plus SVG 1.1//EN")):
-		return "XHTML + MathML + SVG Profile (XHTML as the host language) - DTD"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD XHTML 1.1 plus MathML 2.0 plus SVG 1.1//EN")):
-		return "XHTML + MathML + SVG Profile (Using SVG as the host) - DTD"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD SVG 1.1//EN")):
-		return "SVG 1.1 Full - DTD"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("W3C//DTD SVG 1.0//EN")):
-		return "SVG 1.0 - DTD:"
-	case strings.Contains(strings.ToLower(versionHTML), strings.ToLower("!DOCTYPE HTML")):
-		return "HTML5 and beyond"
-	default:
-		return "undefined"
+	report := &model.ParserResponse{Fetch: result.Meta}
+	ctx = p.withChecks(ctx)
+	for _, analyzer := range analyzers {
+		if analyzer.Name() == AnalyzerScore {
+			// the score rates the page by the issues left once suppressed
+			p.summarizeIssues(ctx, url, report)
+		}
+		if err := analyzer.Analyze(ctx, result, report); err != nil {
+			return nil, errors.Wrapf(err, "analyzer %s", analyzer.Name())
+		}
+		report.Analyzers = append(report.Analyzers, analyzer.Name())
 	}
+	p.summarizeIssues(ctx, url, report)
+	return report, nil
 }
 
-func (p *ParserService) title(doc *goquery.Document) string {
-	var title string
-	doc.Find("title").Each(func(i int, s *goquery.Selection) {
-		// For each item found, get title
-		title = s.Text()
-	})
-	return title
+// Register adds an analyzer to the ones the service runs, after the
+// built-in ones.
+func (p *ParserService) Register(analyzer Analyzer) error {
+	return p.analyzers.Register(analyzer)
 }
 
-func (p *ParserService) header(doc *goquery.Document, level int) []string {
-	var headers []string
-	doc.Find(fmt.Sprintf("h%v", level)).Each(func(i int, s *goquery.Selection) {
-		// For each item found, get the header
-		headers = append(headers, strings.Join(strings.Fields(strings.TrimSpace(s.Text())), " "))
-	})
-	return headers
+// Analyzers lists the names of the registered analyzers in the order they run.
+func (p *ParserService) Analyzers() []string {
+	return p.analyzers.Names()
 }
 
-func (p *ParserService) setInternalLink(ctx context.Context, doc *goquery.Document) ([]*model.Link, []*model.Link) {
-	var internalLink []*model.Link
-	var externalLink []*model.Link
-
-	host := doc.Url.Host
-	doc.Find("a").Each(func(i int, s *goquery.Selection) {
-		// For each item found, get title
-		if href, _ := s.Attr("href"); !strings.Contains(href, "javascript") {
-			link := model.Link{
-				Name: strings.TrimSpace(
-					strings.Replace(
-						strings.Replace(
-							s.Text(), "\n", "", -1),
-						"\t", "", -1),
-				),
-				Url: href,
-			}
-			if len([]rune(href)) > 0 {
-				if strings.Contains(href, host) {
-					internalLink = append(internalLink, &link)
-				} else if []rune(href)[0] == '/' || []rune(href)[0] == '#' || []rune(href)[0] == ':' {
-
-					link.Url = fmt.Sprintf("http://%s/%s", host,
-						strings.Replace(
-							strings.Replace(link.Url,
-								"#", "/", -1),
-							"/", "", -1))
-					internalLink = append(internalLink, &link)
-				} else {
-					externalLink = append(externalLink, &link)
-				}
-			}
+// version reads the doctype from the body as it was served. Documents
+// fetched without their body are rendered back to html.
+func (p *ParserService) version(result *model.FetchResult) *model.HTMLVersion {
+	body := result.Body
+	if body == nil {
+		rendered, err := result.Document.Html()
+		if err != nil {
+			return &model.HTMLVersion{Name: undefinedVersion, Mode: model.ModeQuirks}
 		}
-	})
-
-	p.checkLinks(ctx, internalLink)
-	p.checkLinks(ctx, externalLink)
-
-	return internalLink, externalLink
-}
-
-func (p *ParserService) checkLinks(ctx context.Context, links []*model.Link) {
-	jobPool := make(chan struct{}, p.workerCount)
-
-	for index, link := range links {
-		p.Add(1)
-		jobPool <- struct{}{}
-		go func() {
-			defer func() {
-				<-jobPool
-				p.Done()
-			}()
-			result, err := p.fetcher.IsAccessible(ctx, &model.WorkerWrapper{
-				Index: index,
-				Url:   link.Url,
-			})
-			if err != nil {
-				log.Error(err)
-				return
-			}
-			links[result.Index].Accessible = result.Result
-		}()
-		time.Sleep(50 * time.Millisecond)
+		body = []byte(rendered)
 	}
-	p.Wait()
+	return documentVersion(body)
 }
 
-func (p *ParserService) login(doc *goquery.Document) bool {
-	var formFields []string
-	doc.Find("form").Each(func(i int, s *goquery.Selection) {
-		formFields = append(formFields, processChildren(s)...)
+func (p *ParserService) title(doc *goquery.Document) string {
+	var title string
+	doc.Find("title").Each(func(i int, s *goquery.Selection) {
+		// For each item found, get title
+		title = s.Text()
 	})
-	return model.IsLoginForm(formFields)
-}
-
-func processChildren(s *goquery.Selection) []string {
-	result := make([]string, 0)
-	if s.Children().Length() > 0 {
-		s.Children().Each(func(i int, si *goquery.Selection) {
-			result = append(result, processChildren(si)...)
-		})
-	} else {
-		if text := s.Text(); len(strings.TrimSpace(text)) > 0 {
-			result = append(result, text)
-		}
-	}
-	return result
+	return title
 }
@@ -0,0 +1,209 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const pageURL = "https://www.example.com/"
+
+func pageWithLinks(count int) *model.FetchResult {
+	var body bytes.Buffer
+	body.WriteString("<!DOCTYPE html><html><body>")
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&body, `<a href="https://external%d.com/">link %d</a>`, i, i)
+	}
+	body.WriteString("</body></html>")
+	doc, _ := goquery.NewDocumentFromReader(&body)
+	doc.Url, _ = url.Parse(pageURL)
+	return &model.FetchResult{Document: doc, Meta: &model.FetchMeta{URL: pageURL, FinalURL: pageURL}}
+}
+
+var _ = Describe("Link checking Test", func() {
+
+	var (
+		fetcher *servicefakes.FakeFetcher
+		config  service.ParserConfig
+		parser  *service.ParserService
+	)
+	BeforeEach(func() {
+		fetcher = &servicefakes.FakeFetcher{}
+		config = service.ParserConfig{WorkerCount: 4}
+	})
+	JustBeforeEach(func() {
+		parser = service.NewParserService(fetcher, config)
+	})
+
+	It("should keep the link order whatever order the checks finish in", func() {
+		fetcher.FetchReturns(pageWithLinks(20), nil)
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			// later links finish first
+			time.Sleep(time.Duration(20-pr.Index) * time.Millisecond)
+			return &model.WorkerWrapper{
+				Result:     pr.Index%2 == 0,
+				LinkStatus: model.LinkStatus{StatusCode: 200 + pr.Index},
+			}, nil
+		})
+
+		response, err := parser.Parse(context.Background(), pageURL)
+		Expect(err).To(BeNil())
+		Expect(response.ExternalLinks).To(HaveLen(20))
+		for i, link := range response.ExternalLinks {
+			Expect(link.Url).To(BeEquivalentTo(fmt.Sprintf("https://external%d.com/", i)))
+			Expect(link.Accessible).To(BeEquivalentTo(i%2 == 0))
+			Expect(link.StatusCode).To(BeEquivalentTo(200 + i))
+		}
+	})
+
+	It("should not run more checks than workers", func() {
+		var running, maxRunning int32
+		fetcher.FetchReturns(pageWithLinks(30), nil)
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				seen := atomic.LoadInt32(&maxRunning)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return &model.WorkerWrapper{Result: true}, nil
+		})
+
+		_, err := parser.Parse(context.Background(), pageURL)
+		Expect(err).To(BeNil())
+		Expect(fetcher.IsAccessibleCallCount()).To(BeEquivalentTo(30))
+		Expect(atomic.LoadInt32(&maxRunning)).To(BeNumerically("<=", 4))
+	})
+
+	It("should not make concurrent requests wait on each other", func() {
+		slow := make(chan struct{})
+		fetcher.FetchCalls(func(ctx context.Context, u string) (*model.FetchResult, error) {
+			if u == pageURL+"slow" {
+				return pageWithLinks(1), nil
+			}
+			return pageWithLinks(0), nil
+		})
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			<-slow
+			return &model.WorkerWrapper{Result: true}, nil
+		})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer GinkgoRecover()
+			defer wg.Done()
+			_, err := parser.Parse(context.Background(), pageURL+"slow")
+			Expect(err).To(BeNil())
+		}()
+		Eventually(fetcher.IsAccessibleCallCount).Should(BeEquivalentTo(1))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			parser.Parse(context.Background(), pageURL+"fast")
+		}()
+		Eventually(done).Should(BeClosed())
+
+		close(slow)
+		wg.Wait()
+	})
+
+	It("should check a url found by several analyzers once", func() {
+		doc, _ := goquery.NewDocumentFromReader(bytes.NewBufferString(`<!DOCTYPE html><html><body>
+			<a href="https://cdn.example.org/hero.png">Hero</a>
+			<img src="https://cdn.example.org/hero.png#full" alt="Hero">
+			</body></html>`))
+		doc.Url, _ = url.Parse(pageURL)
+		fetcher.FetchReturns(&model.FetchResult{Document: doc, Meta: &model.FetchMeta{URL: pageURL, FinalURL: pageURL}}, nil)
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			pr.Result = true
+			return pr, nil
+		})
+
+		response, err := parser.Parse(context.Background(), pageURL)
+		Expect(err).To(BeNil())
+		Expect(fetcher.IsAccessibleCallCount()).To(Equal(1))
+		Expect(response.ExternalLinks[0].Accessible).To(BeTrue())
+		Expect(response.Images.Images[0].Accessible).To(BeTrue())
+	})
+
+	It("should stop checking when the request is canceled", func() {
+		fetcher.FetchReturns(pageWithLinks(10), nil)
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			<-ctx.Done()
+			pr.Failure = model.FailureCanceled
+			return pr, nil
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		response, err := parser.Parse(ctx, pageURL)
+		Expect(err).To(BeNil())
+		Expect(fetcher.IsAccessibleCallCount()).To(BeNumerically("<=", 4))
+		for _, link := range response.ExternalLinks {
+			Expect(link.Accessible).To(BeFalse())
+			Expect(link.Failure).To(BeEquivalentTo(model.FailureCanceled))
+		}
+	})
+
+	Context("when the link check deadline passes", func() {
+		BeforeEach(func() {
+			config.LinkCheckTimeout = 20 * time.Millisecond
+		})
+		It("should report unchecked links as timed out", func() {
+			fetcher.FetchReturns(pageWithLinks(10), nil)
+			fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+				<-ctx.Done()
+				pr.Failure = model.FailureTimeout
+				return pr, nil
+			})
+
+			response, err := parser.Parse(context.Background(), pageURL)
+			Expect(err).To(BeNil())
+			Expect(response.ExternalLinks).To(HaveLen(10))
+			for _, link := range response.ExternalLinks {
+				Expect(link.Failure).To(BeEquivalentTo(model.FailureTimeout))
+			}
+		})
+		It("should share the deadline between the analyzers", func() {
+			config.LinkCheckTimeout = 100 * time.Millisecond
+			doc, _ := goquery.NewDocumentFromReader(bytes.NewBufferString(`<!DOCTYPE html><html><head>
+				<script src="https://cdn.example.org/app.js"></script>
+				</head><body>
+				<a href="https://external.com/">link</a>
+				<img src="https://cdn.example.org/hero.png" alt="Hero">
+				</body></html>`))
+			doc.Url, _ = url.Parse(pageURL)
+			fetcher.FetchReturns(&model.FetchResult{Document: doc, Meta: &model.FetchMeta{URL: pageURL, FinalURL: pageURL}}, nil)
+			fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+				<-ctx.Done()
+				pr.Failure = model.FailureTimeout
+				return pr, nil
+			})
+			parser = service.NewParserService(fetcher, config)
+
+			start := time.Now()
+			response, err := parser.Parse(context.Background(), pageURL)
+			Expect(err).To(BeNil())
+			Expect(time.Since(start)).To(BeNumerically("<", 250*time.Millisecond))
+			Expect(response.ExternalLinks[0].Failure).To(BeEquivalentTo(model.FailureTimeout))
+			Expect(response.Images.Images[0].Failure).To(BeEquivalentTo(model.FailureTimeout))
+			Expect(response.Resources[0].Failure).To(BeEquivalentTo(model.FailureTimeout))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	fontFace = regexp.MustCompile(`(?is)@font-face\s*{[^}]*}`)
+	cssURL   = regexp.MustCompile(`(?i)url\(\s*['"]?([^'")]+?)['"]?\s*\)`)
+)
+
+// passiveDestinations are the preload destinations loaded as passive
+// content, the others are active.
+var passiveDestinations = map[string]bool{"image": true, "audio": true, "video": true, "track": true}
+
+// resources lists the scripts, stylesheets, fonts, frames, media and
+// preloads of the document and checks that they can be loaded. Fonts inlined
+// as data urls aren't loaded and aren't listed.
+func (p *ParserService) resources(ctx context.Context, doc *goquery.Document) []*model.Resource {
+	page := documentURL(doc)
+	base := documentBase(doc)
+	ids := countIDs(doc)
+	resources := make([]*model.Resource, 0)
+	add := func(kind string, s *goquery.Selection, src, as string) {
+		src = strings.TrimSpace(src)
+		if src == "" {
+			return
+		}
+		resource := &model.Resource{Kind: kind, Src: src, Url: src, As: as, Selector: selectorPath(s, ids)}
+		if target, err := resolveHref(base, src); err == nil {
+			resource.Url = target.String()
+			resource.ThirdParty = (target.Scheme == "http" || target.Scheme == "https") && !isInternal(page, target)
+			if page.Scheme == "https" && target.Scheme == "http" {
+				resource.MixedContent = model.MixedContentActive
+				if kind == model.ResourceVideo || kind == model.ResourceAudio || passiveDestinations[as] {
+					resource.MixedContent = model.MixedContentPassive
+				}
+			}
+		}
+		resources = append(resources, resource)
+	}
+
+	doc.Find("script[src], link[rel][href], iframe[src], video, audio, style").Each(func(i int, s *goquery.Selection) {
+		switch name := goquery.NodeName(s); name {
+		case "script":
+			add(model.ResourceScript, s, s.AttrOr("src", ""), "")
+		case "iframe":
+			add(model.ResourceIframe, s, s.AttrOr("src", ""), "")
+		case "video", "audio":
+			add(name, s, s.AttrOr("src", ""), "")
+			s.ChildrenFiltered("source[src]").Each(func(i int, source *goquery.Selection) {
+				add(name, source, source.AttrOr("src", ""), "")
+			})
+		case "link":
+			rels := strings.Fields(strings.ToLower(s.AttrOr("rel", "")))
+			as := strings.ToLower(strings.TrimSpace(s.AttrOr("as", "")))
+			switch {
+			case containsString(rels, "stylesheet"):
+				add(model.ResourceStylesheet, s, s.AttrOr("href", ""), "")
+			case containsString(rels, "preload") && as == "font":
+				add(model.ResourceFont, s, s.AttrOr("href", ""), as)
+			case containsString(rels, "preload"):
+				add(model.ResourcePreload, s, s.AttrOr("href", ""), as)
+			case containsString(rels, "modulepreload"):
+				add(model.ResourcePreload, s, s.AttrOr("href", ""), "script")
+			}
+		case "style":
+			for _, face := range fontFace.FindAllString(s.Text(), -1) {
+				for _, m := range cssURL.FindAllStringSubmatch(face, -1) {
+					if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(m[1])), "data:") {
+						add(model.ResourceFont, s, m[1], "")
+					}
+				}
+			}
+		}
+	})
+
+	var urls []string
+	for _, resource := range resources {
+		if checkable(resource.Url) {
+			urls = append(urls, resource.Url)
+		}
+	}
+	results := p.checkUnique(ctx, urls)
+	for _, resource := range resources {
+		if !checkable(resource.Url) {
+			resource.Accessible = true
+			continue
+		}
+		result := results(resource.Url)
+		resource.Accessible = result.Result
+		resource.LinkStatus = result.LinkStatus
+	}
+	return resources
+}
@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"context"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Subresource inventory Test", func() {
+
+	It("should list subresources with their party, mixed content and reachability", func() {
+		fetcher := &servicefakes.FakeFetcher{}
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			pr.Result = pr.Url != "https://cdn.example.net/gone.js"
+			return pr, nil
+		})
+		response := parsePageWith(fetcher, "https://www.example.com/", `<!DOCTYPE html><html><head>
+			<link rel="stylesheet" href="/css/site.css">
+			<link rel="preload" href="https://fonts.example.net/inter.woff2" as="font" crossorigin>
+			<link rel="preload" href="http://img.example.com/hero.jpg" as="image">
+			<link rel="modulepreload" href="/js/app.mjs">
+			<link rel="icon" href="/favicon.ico">
+			<style>@font-face { font-family: Mono; src: url('/fonts/mono.woff2') format("woff2"); } body { background: url(bg.png) }</style>
+			<script src="https://cdn.example.net/gone.js"></script>
+			<script>inline()</script>
+			</head><body>
+			<iframe src="http://maps.example.org/embed"></iframe>
+			<video><source src="http://static.example.com/intro.mp4"></video>
+			</body></html>`, service.ParserConfig{WorkerCount: 2})
+
+		Expect(response.Resources).To(Equal([]*model.Resource{
+			{Kind: model.ResourceStylesheet, Src: "/css/site.css", Url: "https://www.example.com/css/site.css",
+				Selector: "html > head > link:nth-child(1)", Accessible: true},
+			{Kind: model.ResourceFont, Src: "https://fonts.example.net/inter.woff2", Url: "https://fonts.example.net/inter.woff2",
+				As: "font", Selector: "html > head > link:nth-child(2)", ThirdParty: true, Accessible: true},
+			{Kind: model.ResourcePreload, Src: "http://img.example.com/hero.jpg", Url: "http://img.example.com/hero.jpg",
+				As: "image", Selector: "html > head > link:nth-child(3)", MixedContent: model.MixedContentPassive, Accessible: true},
+			{Kind: model.ResourcePreload, Src: "/js/app.mjs", Url: "https://www.example.com/js/app.mjs",
+				As: "script", Selector: "html > head > link:nth-child(4)", Accessible: true},
+			{Kind: model.ResourceFont, Src: "/fonts/mono.woff2", Url: "https://www.example.com/fonts/mono.woff2",
+				Selector: "html > head > style:nth-child(6)", Accessible: true},
+			{Kind: model.ResourceScript, Src: "https://cdn.example.net/gone.js", Url: "https://cdn.example.net/gone.js",
+				Selector: "html > head > script:nth-child(7)", ThirdParty: true},
+			{Kind: model.ResourceIframe, Src: "http://maps.example.org/embed", Url: "http://maps.example.org/embed",
+				Selector: "html > body > iframe:nth-child(1)", ThirdParty: true, MixedContent: model.MixedContentActive, Accessible: true},
+			{Kind: model.ResourceVideo, Src: "http://static.example.com/intro.mp4", Url: "http://static.example.com/intro.mp4",
+				Selector: "html > body > video:nth-child(2) > source", MixedContent: model.MixedContentPassive, Accessible: true},
+		}))
+	})
+
+	It("should list audio but not inline fonts", func() {
+		fetcher := &servicefakes.FakeFetcher{}
+		response := parsePage(fetcher, "https://www.example.com/", `<!DOCTYPE html><html><head>
+			<style>@font-face { font-family: Inline; src: url("data:font/woff2;base64,d09GMgABAAAAAA") format("woff2"); }</style>
+			</head><body>
+			<audio src="http://static.example.com/jingle.mp3"></audio>
+			</body></html>`)
+
+		Expect(response.Resources).To(Equal([]*model.Resource{
+			{Kind: model.ResourceAudio, Src: "http://static.example.com/jingle.mp3", Url: "http://static.example.com/jingle.mp3",
+				Selector: "html > body > audio", MixedContent: model.MixedContentPassive, Accessible: true},
+		}))
+		Expect(fetcher.IsAccessibleCallCount()).To(Equal(1))
+	})
+
+	It("should not report mixed content on http pages", func() {
+		response := parsePage(&servicefakes.FakeFetcher{}, "http://www.example.com/",
+			`<!DOCTYPE html><html><head><script src="http://www.example.com/app.js"></script></head></html>`)
+		Expect(response.Resources).To(HaveLen(1))
+		Expect(response.Resources[0].MixedContent).To(BeEmpty())
+	})
+})
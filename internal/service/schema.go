@@ -0,0 +1,126 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+)
+
+// schemaType lists the properties search engines need, and the ones they
+// recommend, to show rich results for a schema.org type.
+type schemaType struct {
+	required    []string
+	recommended []string
+}
+
+var article = schemaType{
+	required:    []string{"headline"},
+	recommended: []string{"image", "author", "datePublished", "dateModified", "publisher"},
+}
+
+// schemaTypes is the bundled subset of schema.org entities are checked
+// against. Types missing here aren't checked.
+var schemaTypes = map[string]schemaType{
+	"Product": {
+		required:    []string{"name"},
+		recommended: []string{"image", "description", "offers", "brand", "sku", "aggregateRating", "review"},
+	},
+	"Offer": {
+		required:    []string{"price", "priceCurrency"},
+		recommended: []string{"availability", "url"},
+	},
+	"AggregateRating": {
+		required:    []string{"ratingValue"},
+		recommended: []string{"reviewCount", "bestRating"},
+	},
+	"Review": {
+		required:    []string{"author", "reviewRating"},
+		recommended: []string{"datePublished", "reviewBody"},
+	},
+	"Article":     article,
+	"NewsArticle": article,
+	"BlogPosting": article,
+	"Organization": {
+		required:    []string{"name"},
+		recommended: []string{"url", "logo", "sameAs", "contactPoint"},
+	},
+	"LocalBusiness": {
+		required:    []string{"name", "address"},
+		recommended: []string{"telephone", "openingHours", "geo", "url", "image"},
+	},
+	"Person": {
+		required:    []string{"name"},
+		recommended: []string{"url", "image", "sameAs"},
+	},
+	"BreadcrumbList": {
+		required: []string{"itemListElement"},
+	},
+	"ListItem": {
+		required:    []string{"position"},
+		recommended: []string{"name", "item"},
+	},
+	"WebSite": {
+		required:    []string{"url"},
+		recommended: []string{"name", "potentialAction"},
+	},
+	"Event": {
+		required:    []string{"name", "startDate", "location"},
+		recommended: []string{"endDate", "image", "description", "offers", "eventStatus"},
+	},
+	"Recipe": {
+		required:    []string{"name", "image"},
+		recommended: []string{"author", "recipeIngredient", "recipeInstructions", "totalTime"},
+	},
+	"FAQPage": {
+		required: []string{"mainEntity"},
+	},
+	"Question": {
+		required: []string{"name", "acceptedAnswer"},
+	},
+}
+
+// validateEntities reports the required and recommended properties the
+// entities of known types lack.
+func validateEntities(data *model.StructuredData) {
+	for _, entity := range data.Entities {
+		for _, name := range entity.Types {
+			t, ok := schemaTypes[name]
+			if !ok {
+				continue
+			}
+			for _, property := range t.required {
+				if !hasProperty(entity, property) {
+					data.Issues = append(data.Issues, &model.StructuredDataIssue{
+						Code:     model.StructuredMissingRequired,
+						Severity: model.SeverityHigh,
+						Entity:   entity.ID,
+						Type:     name,
+						Property: property,
+						Message:  fmt.Sprintf("%s requires the %s property", name, property),
+					})
+				}
+			}
+			for _, property := range t.recommended {
+				if !hasProperty(entity, property) {
+					data.Issues = append(data.Issues, &model.StructuredDataIssue{
+						Code:     model.StructuredMissingRecommended,
+						Severity: model.SeverityLow,
+						Entity:   entity.ID,
+						Type:     name,
+						Property: property,
+						Message:  fmt.Sprintf("%s should have the %s property", name, property),
+					})
+				}
+			}
+		}
+	}
+}
+
+func hasProperty(entity *model.Entity, name string) bool {
+	for _, value := range entity.Properties[name] {
+		if value.Value != "" || value.Ref != "" {
+			return true
+		}
+	}
+	return false
+}
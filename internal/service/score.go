@@ -0,0 +1,182 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+)
+
+// fact computes a fact from the report, ok is false when the report lacks
+// what the fact needs.
+type fact struct {
+	// analyzer is the analyzer whose section the fact is computed from,
+	// any report has the fact when empty.
+	analyzer string
+	value    func(report *model.ParserResponse) (value float64, ok bool)
+}
+
+var facts = map[string]fact{
+	model.FactTitleLength: {analyzer: AnalyzerMetadata, value: func(report *model.ParserResponse) (float64, bool) {
+		return float64(utf8.RuneCountInString(report.Metadata.Title)), true
+	}},
+	model.FactH1Count: {analyzer: AnalyzerHeadings, value: func(report *model.ParserResponse) (float64, bool) {
+		return float64(len(report.ListH1)), true
+	}},
+	model.FactHeadingIssues: {analyzer: AnalyzerHeadings, value: func(report *model.ParserResponse) (float64, bool) {
+		return float64(len(report.Outline.Issues)), true
+	}},
+	model.FactImageAltCoverage: {analyzer: AnalyzerImages, value: func(report *model.ParserResponse) (float64, bool) {
+		var images, alts int
+		for _, image := range report.Images.Images {
+			if image.Source != model.ImageSourceImg {
+				continue
+			}
+			images++
+			if image.Alt != nil {
+				alts++
+			}
+		}
+		if images == 0 {
+			return 0, false
+		}
+		return float64(alts) / float64(images), true
+	}},
+	model.FactDescriptionLength: {analyzer: AnalyzerMetadata, value: func(report *model.ParserResponse) (float64, bool) {
+		return float64(utf8.RuneCountInString(report.Metadata.Description)), true
+	}},
+	model.FactCanonical: {analyzer: AnalyzerMetadata, value: func(report *model.ParserResponse) (float64, bool) {
+		if report.Metadata.CanonicalURL == "" {
+			return 0, true
+		}
+		for _, issue := range report.Metadata.Issues {
+			if issue.Code == model.MetaCanonicalRelative || issue.Code == model.MetaCanonicalOtherHost {
+				return 0, true
+			}
+		}
+		return 1, true
+	}},
+	model.FactOpenGraphImage: {analyzer: AnalyzerMetadata, value: func(report *model.ParserResponse) (float64, bool) {
+		return yes(report.Metadata.OpenGraph["og:image"] != ""), true
+	}},
+	model.FactBrokenInternalLinks: {analyzer: AnalyzerLinks, value: func(report *model.ParserResponse) (float64, bool) {
+		return float64(inaccessible(report.InternalLinks, issueURLs(report, model.IssueBrokenLink))), true
+	}},
+	model.FactBrokenExternalLinks: {analyzer: AnalyzerLinks, value: func(report *model.ParserResponse) (float64, bool) {
+		return float64(inaccessible(report.ExternalLinks, issueURLs(report, model.IssueBrokenLink))), true
+	}},
+	model.FactQuirksMode: {analyzer: AnalyzerVersion, value: func(report *model.ParserResponse) (float64, bool) {
+		_, reported := issueURLs(report, model.IssueQuirksMode)[""]
+		return yes(report.Version.Mode == model.ModeQuirks && reported), true
+	}},
+	model.FactMixedContent: {analyzer: AnalyzerResources, value: func(report *model.ParserResponse) (float64, bool) {
+		reported := issueURLs(report, model.IssueMixedContent)
+		var count int
+		for _, resource := range report.Resources {
+			if resource.MixedContent != "" && reported[resource.Url] {
+				count++
+			}
+		}
+		return float64(count), true
+	}},
+	model.FactHreflangIssues: {analyzer: AnalyzerI18n, value: func(report *model.ParserResponse) (float64, bool) {
+		var count int
+		for _, issue := range report.I18n.Issues {
+			if strings.HasPrefix(issue.Code, "hreflang-") {
+				count++
+			}
+		}
+		return float64(count), true
+	}},
+}
+
+func yes(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// issueURLs returns the urls of the issues of the report with the code, those
+// suppressed are gone from the report.
+func issueURLs(report *model.ParserResponse, code string) map[string]bool {
+	urls := make(map[string]bool)
+	for _, issue := range report.Issues {
+		if issue.Code == code {
+			urls[issue.URL] = true
+		}
+	}
+	return urls
+}
+
+// inaccessible counts the inaccessible links still reported as broken.
+func inaccessible(links []*model.Link, reported map[string]bool) int {
+	var count int
+	for _, link := range links {
+		if !link.Accessible && reported[link.Url] {
+			count++
+		}
+	}
+	return count
+}
+
+// score rates the page with the scoring rules. Rules whose fact can't be
+// computed from the report are left out, and so are categories without any
+// rule left. The page is unrated, nil, without any category or when the
+// categories have no weight. The facts are computed from the issues left once
+// suppressed.
+func (p *ParserService) score(report *model.ParserResponse) *model.Score {
+	rules := p.config.Scoring
+	names := make([]string, 0, len(rules.Categories))
+	for name := range rules.Categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	score := &model.Score{Categories: make([]*model.CategoryScore, 0, len(names))}
+	var overall, weights float64
+	for _, name := range names {
+		category := p.scoreCategory(name, rules.Categories[name], report)
+		if category == nil {
+			continue
+		}
+		score.Categories = append(score.Categories, category)
+		overall += float64(category.Score) * category.Weight
+		weights += category.Weight
+	}
+	if len(score.Categories) == 0 || weights <= 0 {
+		return nil
+	}
+	score.Overall = int(math.Round(overall / weights))
+	return score
+}
+
+func (p *ParserService) scoreCategory(name string, category *model.ScoreCategory, report *model.ParserResponse) *model.CategoryScore {
+	var earned, weights float64
+	var rules []*model.RuleScore
+	for _, rule := range category.Rules {
+		f, ok := facts[rule.Fact]
+		if !ok || (f.analyzer != "" && !containsString(report.Analyzers, f.analyzer)) {
+			continue
+		}
+		value, ok := f.value(report)
+		if !ok {
+			continue
+		}
+		rate := rule.Rate(value)
+		rules = append(rules, &model.RuleScore{Fact: rule.Fact, Value: value, Weight: rule.Weight, Rate: rate})
+		earned += rate * rule.Weight
+		weights += rule.Weight
+	}
+	if weights <= 0 {
+		return nil
+	}
+	return &model.CategoryScore{
+		Name:   name,
+		Score:  int(math.Round(100 * earned / weights)),
+		Weight: category.Weight,
+		Rules:  rules,
+	}
+}
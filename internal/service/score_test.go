@@ -0,0 +1,154 @@
+package service_test
+
+import (
+	"context"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Score Test", func() {
+
+	bound := func(value float64) *float64 {
+		return &value
+	}
+
+	table.DescribeTable("should rate facts against the thresholds",
+		func(rule model.ScoreRule, value, rate float64) {
+			Expect(rule.Rate(value)).To(BeNumerically("~", rate, 1e-9))
+		},
+		table.Entry("within the bounds", model.ScoreRule{Min: bound(10), Max: bound(60)}, 30.0, 1.0),
+		table.Entry("on a bound", model.ScoreRule{Min: bound(10), Max: bound(60)}, 60.0, 1.0),
+		table.Entry("below without tolerance", model.ScoreRule{Min: bound(1)}, 0.0, 0.0),
+		table.Entry("above within the tolerance", model.ScoreRule{Max: bound(60), Tolerance: 20}, 70.0, 0.5),
+		table.Entry("below within the tolerance", model.ScoreRule{Min: bound(1), Tolerance: 1}, 0.75, 0.75),
+		table.Entry("beyond the tolerance", model.ScoreRule{Max: bound(0), Tolerance: 5}, 8.0, 0.0),
+		table.Entry("unbounded", model.ScoreRule{}, 1000.0, 1.0),
+	)
+
+	const page = `<!DOCTYPE html><html lang="en"><head>
+		<title>An example page with a good title</title>
+		<link rel="alternate" hreflang="de" href="https://www.example.com/de/">
+		<link rel="alternate" hreflang="de" href="https://www.example.com/at/">
+		</head><body><main>
+		<h1>Example</h1>
+		<img src="/a.png" alt="A"><img src="/b.png">
+		<a href="/missing">missing</a><a href="/about">about</a>
+		</main></body></html>`
+
+	var fetcher *servicefakes.FakeFetcher
+
+	BeforeEach(func() {
+		fetcher = &servicefakes.FakeFetcher{}
+		fetcher.IsAccessibleCalls(func(ctx context.Context, pr *model.WorkerWrapper) (*model.WorkerWrapper, error) {
+			pr.Result = pr.Url != "https://www.example.com/missing"
+			return pr, nil
+		})
+	})
+
+	categories := func(score *model.Score) map[string]int {
+		scores := make(map[string]int)
+		for _, category := range score.Categories {
+			scores[category.Name] = category.Score
+		}
+		return scores
+	}
+
+	It("should score the categories and the page", func() {
+		score := parsePage(fetcher, pageURL, page).Score
+
+		// content: title 3/3, h1 2/2, headings 1/1, alt coverage 0.5*2 of 8
+		// metadata: description 0/3, canonical 0/2, og:image 0/1
+		// links: broken internal 0.8*3, external 1*1 of 4
+		// technical: quirks 2/2, mixed content 2/2, hreflang 0*1 of 5 for
+		// a duplicate and no self-reference nor x-default
+		Expect(categories(score)).To(Equal(map[string]int{
+			model.CategoryContent:   88,
+			model.CategoryMetadata:  0,
+			model.CategoryLinks:     85,
+			model.CategoryTechnical: 80,
+		}))
+		Expect(score.Overall).To(Equal(63))
+		Expect(score.Categories[0].Name).To(Equal(model.CategoryContent))
+		Expect(score.Categories[0].Rules).To(ContainElement(&model.RuleScore{
+			Fact: model.FactImageAltCoverage, Value: 0.5, Weight: 2, Rate: 0.5,
+		}))
+	})
+
+	It("should not count the suppressed issues", func() {
+		score := parsePageWith(fetcher, pageURL, page, service.ParserConfig{
+			Suppressions: []*model.Suppression{{Code: model.IssueBrokenLink}, {Code: "hreflang-*"}},
+		}).Score
+
+		Expect(categories(score)).To(Equal(map[string]int{
+			model.CategoryContent:   88,
+			model.CategoryMetadata:  0,
+			model.CategoryLinks:     100,
+			model.CategoryTechnical: 100,
+		}))
+	})
+
+	It("should leave out the facts of the analyzers which didn't run", func() {
+		ctx := service.WithAnalyzers(context.Background(), []string{service.AnalyzerHeadings, service.AnalyzerScore})
+		parsePage(fetcher, pageURL, page)
+		response, err := service.NewParserService(fetcher, service.ParserConfig{}).Parse(ctx, pageURL)
+		Expect(err).To(BeNil())
+
+		Expect(categories(response.Score)).To(Equal(map[string]int{
+			model.CategoryContent: 100,
+		}))
+		Expect(response.Score.Overall).To(Equal(100))
+	})
+
+	table.DescribeTable("should leave the page unrated without any category",
+		func(analyzers []string) {
+			ctx := service.WithAnalyzers(context.Background(), analyzers)
+			parsePage(fetcher, pageURL, page)
+			response, err := service.NewParserService(fetcher, service.ParserConfig{}).Parse(ctx, pageURL)
+			Expect(err).To(BeNil())
+
+			Expect(response.Score).To(BeNil())
+		},
+		table.Entry("score alone", []string{service.AnalyzerScore}),
+		table.Entry("title and score", []string{service.AnalyzerTitle, service.AnalyzerScore}),
+	)
+
+	It("should score with the configured rules", func() {
+		score := parsePageWith(fetcher, pageURL, page, service.ParserConfig{
+			Scoring: &model.ScoringRules{Categories: map[string]*model.ScoreCategory{
+				"strict": {Weight: 2, Rules: []*model.ScoreRule{
+					{Fact: model.FactTitleLength, Weight: 1, Max: bound(20), Tolerance: 26},
+					{Fact: model.FactBrokenInternalLinks, Weight: 1, Max: bound(0)},
+				}},
+				"empty": {Weight: 1, Rules: []*model.ScoreRule{{Fact: "unknown", Weight: 1}}},
+			}},
+		}).Score
+
+		Expect(score).To(Equal(&model.Score{
+			Overall: 25,
+			Categories: []*model.CategoryScore{{
+				Name:   "strict",
+				Score:  25,
+				Weight: 2,
+				Rules: []*model.RuleScore{
+					{Fact: model.FactTitleLength, Value: 33, Weight: 1, Rate: 0.5},
+					{Fact: model.FactBrokenInternalLinks, Value: 1, Weight: 1, Rate: 0},
+				},
+			}},
+		}))
+	})
+
+	It("should leave the page unrated without category weights", func() {
+		score := parsePageWith(fetcher, pageURL, page, service.ParserConfig{
+			Scoring: &model.ScoringRules{Categories: map[string]*model.ScoreCategory{
+				"unweighted": {Rules: []*model.ScoreRule{{Fact: model.FactTitleLength, Weight: 1}}},
+			}},
+		}).Score
+
+		Expect(score).To(BeNil())
+	})
+})
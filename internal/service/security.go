@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxAuditedFrames bounds the cross-origin frames fetched to look for
+// password fields.
+const maxAuditedFrames = 3
+
+var csrfField = regexp.MustCompile(`(?i)csrf|xsrf|authenticity|token|nonce|verification`)
+
+// auditLogin checks the forms the login detector flags for weaknesses
+// exposing the credentials. The cross-origin frames are only fetched and
+// checked on pages asking for a password.
+func (p *ParserService) auditLogin(ctx context.Context, doc *goquery.Document, forms []*model.Form) []*model.SecurityIssue {
+	page := documentURL(doc)
+	var formIssues []*model.SecurityIssue
+	login := false
+	doc.Find("form").Each(func(i int, s *goquery.Selection) {
+		if form := forms[i]; form.LoginScore >= p.config.LoginThreshold {
+			login = true
+			formIssues = append(formIssues, auditLoginForm(page, s, form)...)
+		}
+	})
+
+	issues := make([]*model.SecurityIssue, 0)
+	if login && page.Scheme == "http" {
+		issues = append(issues, &model.SecurityIssue{
+			Code:     model.SecurityInsecurePage,
+			Severity: model.SeverityHigh,
+			Message:  "the login page is served over plain http",
+			URL:      page.String(),
+		})
+	}
+	issues = append(issues, formIssues...)
+	if login || hasPasswordField(doc.Selection) {
+		issues = append(issues, p.auditFrames(ctx, doc)...)
+	}
+	return issues
+}
+
+func auditLoginForm(page *url.URL, s *goquery.Selection, form *model.Form) []*model.SecurityIssue {
+	var issues []*model.SecurityIssue
+	issue := func(code, severity, message string) {
+		issues = append(issues, &model.SecurityIssue{
+			Code:     code,
+			Severity: severity,
+			Message:  message,
+			Selector: form.Selector,
+			URL:      form.Action,
+		})
+	}
+
+	action, err := url.Parse(form.Action)
+	if err == nil {
+		if action.Scheme == "http" {
+			issue(model.SecurityInsecureAction, model.SeverityHigh, "the form posts credentials over plain http")
+		}
+		if origin(action) != origin(page) {
+			issue(model.SecurityCrossOriginAction, model.SeverityMedium, "the form posts credentials to another origin")
+		}
+	}
+	if form.Method == "get" {
+		issue(model.SecurityCredentialsInURL, model.SeverityHigh, "the form sends credentials in the url")
+	}
+
+	autocompleteOff := strings.EqualFold(strings.TrimSpace(s.AttrOr("autocomplete", "")), "off")
+	hasToken := false
+	for _, input := range form.Inputs {
+		if input.Type == "password" && input.Autocomplete == "off" {
+			autocompleteOff = true
+		}
+		if input.Type == "hidden" && csrfField.MatchString(input.Name) {
+			hasToken = true
+		}
+	}
+	if autocompleteOff {
+		issue(model.SecurityAutocompleteOff, model.SeverityLow,
+			"autocomplete=off keeps password managers from filling in strong passwords")
+	}
+	if form.Method == "post" && !hasToken {
+		issue(model.SecurityMissingCSRFToken, model.SeverityMedium, "the form has no hidden anti-CSRF token")
+	}
+	return issues
+}
+
+// auditFrames fetches the first cross-origin frames of the page and reports
+// those asking for a password.
+func (p *ParserService) auditFrames(ctx context.Context, doc *goquery.Document) []*model.SecurityIssue {
+	page := documentURL(doc)
+	base := documentBase(doc)
+	ids := countIDs(doc)
+	ctx, cancel := p.checkContext(ctx)
+	defer cancel()
+
+	var issues []*model.SecurityIssue
+	fetched := 0
+	doc.Find("iframe[src]").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		target, err := resolveHref(base, strings.TrimSpace(s.AttrOr("src", "")))
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || origin(target) == origin(page) {
+			return true
+		}
+		fetched++
+		result, err := p.fetcher.Fetch(ctx, target.String())
+		if err == nil && result != nil && result.Document != nil && hasPasswordField(result.Document.Selection) {
+			issues = append(issues, &model.SecurityIssue{
+				Code:     model.SecurityCrossOriginPassword,
+				Severity: model.SeverityMedium,
+				Message:  "a frame from another origin asks for a password",
+				Selector: selectorPath(s, ids),
+				URL:      target.String(),
+			})
+		}
+		return fetched < maxAuditedFrames && ctx.Err() == nil
+	})
+	return issues
+}
+
+func hasPasswordField(s *goquery.Selection) bool {
+	found := false
+	s.Find("input").EachWithBreak(func(i int, input *goquery.Selection) bool {
+		found = strings.EqualFold(strings.TrimSpace(input.AttrOr("type", "")), "password")
+		return !found
+	})
+	return found
+}
+
+// origin returns the scheme, host and port of the url, with the default port
+// of the scheme made explicit.
+func origin(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		}
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Hostname()) + ":" + port
+}
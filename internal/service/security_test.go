@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	"github.com/PuerkitoBio/goquery"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Login security Test", func() {
+
+	codes := func(issues []*model.SecurityIssue) []string {
+		result := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			result = append(result, issue.Code)
+		}
+		return result
+	}
+
+	table.DescribeTable("should audit login forms",
+		func(page, form string, expected ...string) {
+			response := parsePage(&servicefakes.FakeFetcher{}, page,
+				fmt.Sprintf(`<!DOCTYPE html><html><body>%s</body></html>`, form))
+
+			Expect(codes(response.LoginSecurity)).To(Equal(append([]string{}, expected...)))
+		},
+		table.Entry("secure form", "https://www.example.com/", `<form method="post" action="/session">
+				<input name="username"><input type="password" name="password">
+				<input type="hidden" name="authenticity_token" value="x">
+				<button>Sign in</button>
+			</form>`),
+		table.Entry("page over http", "http://www.example.com/", `<form method="post" action="/session">
+				<input name="username"><input type="password" name="password">
+				<button>Sign in</button>
+			</form>`, model.SecurityInsecurePage, model.SecurityInsecureAction, model.SecurityMissingCSRFToken),
+		table.Entry("action on another origin", "https://www.example.com/", `<form method="post" action="https://auth.example.net/session">
+				<input name="username"><input type="password" name="password">
+				<input type="hidden" name="csrf" value="x">
+				<button>Sign in</button>
+			</form>`, model.SecurityCrossOriginAction),
+		table.Entry("credentials in url", "https://www.example.com/", `<form autocomplete="off">
+				<input name="username"><input type="password" name="password">
+				<button>Sign in</button>
+			</form>`, model.SecurityCredentialsInURL, model.SecurityAutocompleteOff),
+		table.Entry("password autocomplete off", "https://www.example.com/", `<form method="post">
+				<input name="username"><input type="password" name="password" autocomplete="off">
+				<input type="hidden" name="_token" value="x">
+				<button>Sign in</button>
+			</form>`, model.SecurityAutocompleteOff),
+		table.Entry("search form over http", "http://www.example.com/", `<form action="/search">
+				<input type="search" name="q"><button>Search</button>
+			</form>`),
+	)
+
+	Describe("cross-origin frames", func() {
+		var (
+			frames  map[string]string
+			fetcher *servicefakes.FakeFetcher
+		)
+		BeforeEach(func() {
+			frames = map[string]string{
+				"https://login.example.net/frame": `<form><input type="Password"></form>`,
+				"https://widgets.example.org/":    `<p>weather</p>`,
+			}
+			fetcher = &servicefakes.FakeFetcher{}
+			fetcher.FetchCalls(func(ctx context.Context, rawURL string) (*model.FetchResult, error) {
+				doc, err := goquery.NewDocumentFromReader(strings.NewReader(
+					"<!DOCTYPE html><html><body>" + frames[rawURL] + "</body></html>"))
+				Expect(err).NotTo(HaveOccurred())
+				doc.Url, _ = url.Parse(rawURL)
+				return &model.FetchResult{Document: doc, Meta: &model.FetchMeta{URL: rawURL, FinalURL: rawURL}}, nil
+			})
+		})
+		parse := func() *model.ParserResponse {
+			response, err := service.NewParserService(fetcher, service.ParserConfig{}).Parse(context.Background(), "https://www.example.com/")
+			Expect(err).NotTo(HaveOccurred())
+			return response
+		}
+
+		It("should report frames asking for a password on pages asking for one", func() {
+			frames["https://www.example.com/"] = `<input type="password">` +
+				`<iframe src="/same"></iframe><iframe src="https://login.example.net/frame"></iframe><iframe src="https://widgets.example.org/"></iframe>`
+
+			response := parse()
+			Expect(fetcher.FetchCallCount()).To(Equal(3))
+			Expect(response.LoginSecurity).To(Equal([]*model.SecurityIssue{{
+				Code:     model.SecurityCrossOriginPassword,
+				Severity: model.SeverityMedium,
+				Message:  "a frame from another origin asks for a password",
+				Selector: "html > body > iframe:nth-child(3)",
+				URL:      "https://login.example.net/frame",
+			}}))
+		})
+
+		It("should not fetch the frames of pages without a login form", func() {
+			frames["https://www.example.com/"] = `<iframe src="https://widgets.example.org/"></iframe>`
+
+			response := parse()
+			Expect(fetcher.FetchCallCount()).To(Equal(1))
+			Expect(response.LoginSecurity).To(BeEmpty())
+		})
+	})
+})
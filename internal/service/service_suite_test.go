@@ -0,0 +1,15 @@
+package service_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+func TestService(t *testing.T) {
+	logrus.SetLevel(logrus.ErrorLevel)
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Service")
+}
@@ -7,21 +7,21 @@ import (
 
 	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
 	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service"
-	"github.com/PuerkitoBio/goquery"
 )
 
 type FakeFetcher struct {
-	FetchStub        func(string) (*goquery.Document, error)
+	FetchStub        func(context.Context, string) (*model.FetchResult, error)
 	fetchMutex       sync.RWMutex
 	fetchArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
+		arg2 string
 	}
 	fetchReturns struct {
-		result1 *goquery.Document
+		result1 *model.FetchResult
 		result2 error
 	}
 	fetchReturnsOnCall map[int]struct {
-		result1 *goquery.Document
+		result1 *model.FetchResult
 		result2 error
 	}
 	IsAccessibleStub        func(context.Context, *model.WorkerWrapper) (*model.WorkerWrapper, error)
@@ -42,18 +42,19 @@ type FakeFetcher struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeFetcher) Fetch(arg1 string) (*goquery.Document, error) {
+func (fake *FakeFetcher) Fetch(arg1 context.Context, arg2 string) (*model.FetchResult, error) {
 	fake.fetchMutex.Lock()
 	ret, specificReturn := fake.fetchReturnsOnCall[len(fake.fetchArgsForCall)]
 	fake.fetchArgsForCall = append(fake.fetchArgsForCall, struct {
-		arg1 string
-	}{arg1})
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
 	stub := fake.FetchStub
 	fakeReturns := fake.fetchReturns
-	fake.recordInvocation("Fetch", []interface{}{arg1})
+	fake.recordInvocation("Fetch", []interface{}{arg1, arg2})
 	fake.fetchMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -67,41 +68,41 @@ func (fake *FakeFetcher) FetchCallCount() int {
 	return len(fake.fetchArgsForCall)
 }
 
-func (fake *FakeFetcher) FetchCalls(stub func(string) (*goquery.Document, error)) {
+func (fake *FakeFetcher) FetchCalls(stub func(context.Context, string) (*model.FetchResult, error)) {
 	fake.fetchMutex.Lock()
 	defer fake.fetchMutex.Unlock()
 	fake.FetchStub = stub
 }
 
-func (fake *FakeFetcher) FetchArgsForCall(i int) string {
+func (fake *FakeFetcher) FetchArgsForCall(i int) (context.Context, string) {
 	fake.fetchMutex.RLock()
 	defer fake.fetchMutex.RUnlock()
 	argsForCall := fake.fetchArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeFetcher) FetchReturns(result1 *goquery.Document, result2 error) {
+func (fake *FakeFetcher) FetchReturns(result1 *model.FetchResult, result2 error) {
 	fake.fetchMutex.Lock()
 	defer fake.fetchMutex.Unlock()
 	fake.FetchStub = nil
 	fake.fetchReturns = struct {
-		result1 *goquery.Document
+		result1 *model.FetchResult
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeFetcher) FetchReturnsOnCall(i int, result1 *goquery.Document, result2 error) {
+func (fake *FakeFetcher) FetchReturnsOnCall(i int, result1 *model.FetchResult, result2 error) {
 	fake.fetchMutex.Lock()
 	defer fake.fetchMutex.Unlock()
 	fake.FetchStub = nil
 	if fake.fetchReturnsOnCall == nil {
 		fake.fetchReturnsOnCall = make(map[int]struct {
-			result1 *goquery.Document
+			result1 *model.FetchResult
 			result2 error
 		})
 	}
 	fake.fetchReturnsOnCall[i] = struct {
-		result1 *goquery.Document
+		result1 *model.FetchResult
 		result2 error
 	}{result1, result2}
 }
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Prefixes naming the schema.org vocabulary, stripped from types and
+// property names.
+var schemaPrefixes = []string{"http://schema.org/", "https://schema.org/", "schema:"}
+
+// graphBuilder flattens the entities found in a document into one graph.
+// Entities declared more than once with the same identifier are merged.
+type graphBuilder struct {
+	base   *url.URL
+	data   *model.StructuredData
+	ids    map[string]*model.Entity
+	blanks int
+
+	// elementIDs counts the ids of the document elements.
+	elementIDs idCounts
+}
+
+// structuredData extracts the JSON-LD, Microdata and RDFa entities of the
+// document and validates them against the bundled schema.org subset.
+func (p *ParserService) structuredData(doc *goquery.Document) *model.StructuredData {
+	g := &graphBuilder{
+		base:       documentBase(doc),
+		elementIDs: countIDs(doc),
+		ids:        make(map[string]*model.Entity),
+		data: &model.StructuredData{
+			Entities: make([]*model.Entity, 0),
+			Issues:   make([]*model.StructuredDataIssue, 0),
+		},
+	}
+	doc.Find(`script[type]`).Each(func(i int, s *goquery.Selection) {
+		if strings.EqualFold(strings.TrimSpace(s.AttrOr("type", "")), "application/ld+json") {
+			g.jsonLD(s)
+		}
+	})
+	doc.Find("[itemscope]").Each(func(i int, s *goquery.Selection) {
+		if _, property := s.Attr("itemprop"); !property || s.ParentsFiltered("[itemscope]").Length() == 0 {
+			g.microdataItem(s)
+		}
+	})
+	doc.Find("[typeof]").Each(func(i int, s *goquery.Selection) {
+		if _, property := s.Attr("property"); !property || s.ParentsFiltered("[typeof]").Length() == 0 {
+			g.rdfaItem(s, rdfaVocabulary(s))
+		}
+	})
+	validateEntities(g.data)
+	return g.data
+}
+
+// entity adds an entity to the graph, naming it by a blank node when it has
+// no identifier, or returns the entity already known by the identifier.
+func (g *graphBuilder) entity(id, source string, types []string) *model.Entity {
+	if entity, ok := g.ids[id]; ok {
+		for _, t := range types {
+			if t = schemaTerm(t); !containsString(entity.Types, t) {
+				entity.Types = append(entity.Types, t)
+			}
+		}
+		return entity
+	}
+	if id == "" {
+		id = fmt.Sprintf("_:b%d", g.blanks)
+		g.blanks++
+	}
+	entity := &model.Entity{
+		ID:         id,
+		Types:      make([]string, 0, len(types)),
+		Source:     source,
+		Properties: make(map[string][]*model.PropertyValue),
+	}
+	for _, t := range types {
+		entity.Types = append(entity.Types, schemaTerm(t))
+	}
+	g.data.Entities = append(g.data.Entities, entity)
+	g.ids[id] = entity
+	return entity
+}
+
+func (g *graphBuilder) resolve(ref string) string {
+	if target, err := g.base.Parse(strings.TrimSpace(ref)); err == nil {
+		return target.String()
+	}
+	return ref
+}
+
+func schemaTerm(term string) string {
+	term = strings.TrimSpace(term)
+	for _, prefix := range schemaPrefixes {
+		if strings.HasPrefix(term, prefix) {
+			return term[len(prefix):]
+		}
+	}
+	return term
+}
+
+func addProperty(entity *model.Entity, name string, value *model.PropertyValue) {
+	name = schemaTerm(name)
+	entity.Properties[name] = append(entity.Properties[name], value)
+}
+
+// jsonLD reads the entities of a JSON-LD script: a node, an array of nodes
+// or a node with a @graph.
+func (g *graphBuilder) jsonLD(s *goquery.Selection) {
+	var document interface{}
+	if err := json.Unmarshal([]byte(s.Text()), &document); err != nil {
+		g.data.Issues = append(g.data.Issues, &model.StructuredDataIssue{
+			Code:     model.StructuredInvalidJSONLD,
+			Severity: model.SeverityMedium,
+			Message:  fmt.Sprintf("the JSON-LD block at %s can't be parsed: %s", selectorPath(s, g.elementIDs), err),
+		})
+		return
+	}
+	var nodes []interface{}
+	switch document := document.(type) {
+	case []interface{}:
+		nodes = document
+	case map[string]interface{}:
+		if graph, ok := document["@graph"].([]interface{}); ok {
+			nodes = graph
+		} else {
+			nodes = []interface{}{document}
+		}
+	}
+	for _, node := range nodes {
+		if node, ok := node.(map[string]interface{}); ok {
+			g.jsonLDNode(node)
+		}
+	}
+}
+
+func (g *graphBuilder) jsonLDNode(node map[string]interface{}) *model.Entity {
+	id, _ := node["@id"].(string)
+	entity := g.entity(g.jsonLDID(id), model.SourceJSONLD, jsonLDStrings(node["@type"]))
+
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		if !strings.HasPrefix(key, "@") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values, ok := node[key].([]interface{})
+		if !ok {
+			values = []interface{}{node[key]}
+		}
+		for _, value := range values {
+			if value := g.jsonLDValue(value); value != nil {
+				addProperty(entity, key, value)
+			}
+		}
+	}
+	return entity
+}
+
+func (g *graphBuilder) jsonLDValue(value interface{}) *model.PropertyValue {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return &model.PropertyValue{Value: value}
+	case map[string]interface{}:
+		if v, ok := value["@value"]; ok {
+			return g.jsonLDValue(v)
+		}
+		if id, ok := value["@id"].(string); ok && len(value) == 1 {
+			return &model.PropertyValue{Ref: g.jsonLDID(id)}
+		}
+		return &model.PropertyValue{Ref: g.jsonLDNode(value).ID}
+	case []interface{}:
+		return nil
+	default:
+		return &model.PropertyValue{Value: fmt.Sprint(value)}
+	}
+}
+
+// jsonLDID resolves a node identifier like the RDFa ones, blank node
+// identifiers are kept as they are.
+func (g *graphBuilder) jsonLDID(id string) string {
+	if strings.TrimSpace(id) == "" || strings.HasPrefix(id, "_:") {
+		return id
+	}
+	return g.resolve(id)
+}
+
+func jsonLDStrings(value interface{}) []string {
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var result []string
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// microdataItem reads an item and, through its properties, the items nested
+// in it. Properties of nested items belong to those items only.
+func (g *graphBuilder) microdataItem(s *goquery.Selection) *model.Entity {
+	entity := g.entity(strings.TrimSpace(s.AttrOr("itemid", "")), model.SourceMicrodata,
+		strings.Fields(s.AttrOr("itemtype", "")))
+	walkChildren(s, func(child *goquery.Selection) bool {
+		_, scope := child.Attr("itemscope")
+		names := strings.Fields(child.AttrOr("itemprop", ""))
+		if len(names) == 0 {
+			return !scope
+		}
+		var value *model.PropertyValue
+		if scope {
+			value = &model.PropertyValue{Ref: g.microdataItem(child).ID}
+		} else {
+			value = &model.PropertyValue{Value: g.microdataValue(child)}
+		}
+		for _, name := range names {
+			addProperty(entity, name, value)
+		}
+		return !scope
+	})
+	return entity
+}
+
+func (g *graphBuilder) microdataValue(s *goquery.Selection) string {
+	switch goquery.NodeName(s) {
+	case "meta":
+		return s.AttrOr("content", "")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return g.resolve(s.AttrOr("src", ""))
+	case "a", "area", "link":
+		return g.resolve(s.AttrOr("href", ""))
+	case "object":
+		return g.resolve(s.AttrOr("data", ""))
+	case "data", "meter":
+		return s.AttrOr("value", "")
+	case "time":
+		return s.AttrOr("datetime", normalizeSpace(s.Text()))
+	}
+	return normalizeSpace(s.Text())
+}
+
+// rdfaVocabulary returns the vocabulary in scope of the element.
+func rdfaVocabulary(s *goquery.Selection) string {
+	if vocab, ok := s.Attr("vocab"); ok {
+		return vocab
+	}
+	if parent := s.ParentsFiltered("[vocab]").First(); parent.Length() > 0 {
+		return parent.AttrOr("vocab", "")
+	}
+	return ""
+}
+
+// rdfaItem reads an RDFa Lite resource: the element with typeof and the
+// properties below it up to the next typeof.
+func (g *graphBuilder) rdfaItem(s *goquery.Selection, vocab string) *model.Entity {
+	id := s.AttrOr("resource", s.AttrOr("about", ""))
+	if id != "" {
+		id = g.resolve(id)
+	}
+	var types []string
+	for _, t := range strings.Fields(s.AttrOr("typeof", "")) {
+		types = append(types, rdfaTerm(t, vocab))
+	}
+	entity := g.entity(id, model.SourceRDFa, types)
+	walkChildren(s, func(child *goquery.Selection) bool {
+		childVocab := vocab
+		if v, ok := child.Attr("vocab"); ok {
+			childVocab = v
+		}
+		_, typed := child.Attr("typeof")
+		names := strings.Fields(child.AttrOr("property", ""))
+		if len(names) == 0 {
+			return !typed
+		}
+		var value *model.PropertyValue
+		if typed {
+			value = &model.PropertyValue{Ref: g.rdfaItem(child, childVocab).ID}
+		} else {
+			value = &model.PropertyValue{Value: g.rdfaValue(child)}
+		}
+		for _, name := range names {
+			addProperty(entity, rdfaTerm(name, childVocab), value)
+		}
+		return !typed
+	})
+	return entity
+}
+
+func (g *graphBuilder) rdfaValue(s *goquery.Selection) string {
+	if content, ok := s.Attr("content"); ok {
+		return content
+	}
+	for _, attr := range []string{"resource", "href", "src"} {
+		if ref, ok := s.Attr(attr); ok {
+			return g.resolve(ref)
+		}
+	}
+	if datetime, ok := s.Attr("datetime"); ok {
+		return datetime
+	}
+	return normalizeSpace(s.Text())
+}
+
+// rdfaTerm expands a term relative to the vocabulary, so that it loses the
+// schema.org prefix like JSON-LD and Microdata terms do.
+func rdfaTerm(term, vocab string) string {
+	if !strings.Contains(term, ":") && vocab != "" {
+		term = strings.TrimSuffix(vocab, "/") + "/" + term
+	}
+	return schemaTerm(term)
+}
@@ -0,0 +1,138 @@
+package service_test
+
+import (
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/model"
+	"github.com/Dmitriy-Opria/re_web_page_analyzer/internal/service/servicefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Structured data Test", func() {
+
+	const page = "https://shop.example.com/products/1"
+
+	value := func(v string) []*model.PropertyValue {
+		return []*model.PropertyValue{{Value: v}}
+	}
+	ref := func(id string) []*model.PropertyValue {
+		return []*model.PropertyValue{{Ref: id}}
+	}
+	parse := func(body string) *model.StructuredData {
+		return parsePage(&servicefakes.FakeFetcher{}, page,
+			`<!DOCTYPE html><html><head></head><body>`+body+`</body></html>`).StructuredData
+	}
+
+	It("should read JSON-LD graphs", func() {
+		data := parse(`<script type="application/ld+json">
+			{"@context": "https://schema.org", "@graph": [
+				{"@type": "Organization", "@id": "#org", "name": "Example", "url": "https://example.com/"},
+				{"@type": "schema:Product", "name": "Kettle", "brand": {"@id": "#org"},
+				 "offers": {"@type": "Offer", "price": 25.5, "priceCurrency": "EUR"}}
+			]}
+			</script>`)
+
+		Expect(data.Entities).To(Equal([]*model.Entity{
+			{ID: "https://shop.example.com/products/1#org", Types: []string{"Organization"}, Source: model.SourceJSONLD, Properties: map[string][]*model.PropertyValue{
+				"name": value("Example"),
+				"url":  value("https://example.com/"),
+			}},
+			{ID: "_:b0", Types: []string{"Product"}, Source: model.SourceJSONLD, Properties: map[string][]*model.PropertyValue{
+				"brand":  ref("https://shop.example.com/products/1#org"),
+				"name":   value("Kettle"),
+				"offers": ref("_:b1"),
+			}},
+			{ID: "_:b1", Types: []string{"Offer"}, Source: model.SourceJSONLD, Properties: map[string][]*model.PropertyValue{
+				"price":         value("25.5"),
+				"priceCurrency": value("EUR"),
+			}},
+		}))
+	})
+
+	It("should read Microdata items", func() {
+		data := parse(`<div itemscope itemtype="https://schema.org/BreadcrumbList">
+				<span itemprop="itemListElement" itemscope itemtype="https://schema.org/ListItem">
+					<a itemprop="item" href="/products/"><span itemprop="name">Products</span></a>
+					<meta itemprop="position" content="1">
+				</span>
+			</div>`)
+
+		Expect(data.Entities).To(Equal([]*model.Entity{
+			{ID: "_:b0", Types: []string{"BreadcrumbList"}, Source: model.SourceMicrodata, Properties: map[string][]*model.PropertyValue{
+				"itemListElement": ref("_:b1"),
+			}},
+			{ID: "_:b1", Types: []string{"ListItem"}, Source: model.SourceMicrodata, Properties: map[string][]*model.PropertyValue{
+				"item":     value("https://shop.example.com/products/"),
+				"name":     value("Products"),
+				"position": value("1"),
+			}},
+		}))
+		Expect(data.Issues).To(BeEmpty())
+	})
+
+	It("should read RDFa resources", func() {
+		data := parse(`<div vocab="https://schema.org/" typeof="Article" resource="#post">
+				<h1 property="headline">Kettles  compared</h1>
+				<span property="author" typeof="Person"><span property="name">Ann</span></span>
+				<time property="datePublished" datetime="2020-11-01">November</time>
+			</div>`)
+
+		Expect(data.Entities).To(Equal([]*model.Entity{
+			{ID: "https://shop.example.com/products/1#post", Types: []string{"Article"}, Source: model.SourceRDFa, Properties: map[string][]*model.PropertyValue{
+				"headline":      value("Kettles compared"),
+				"author":        ref("_:b0"),
+				"datePublished": value("2020-11-01"),
+			}},
+			{ID: "_:b0", Types: []string{"Person"}, Source: model.SourceRDFa, Properties: map[string][]*model.PropertyValue{
+				"name": value("Ann"),
+			}},
+		}))
+	})
+
+	It("should report the properties entities lack", func() {
+		data := parse(`<script type="application/ld+json">
+			[{"@type": "Product", "name": "Kettle", "image": "k.png", "description": "A kettle",
+			  "offers": {"@type": "Offer", "priceCurrency": "EUR", "availability": "InStock", "url": "/k"},
+			  "brand": "Acme", "sku": "K1", "aggregateRating": {"@type": "AggregateRating", "ratingValue": "4", "reviewCount": "3", "bestRating": "5"},
+			  "review": "Good"}]
+			</script>
+			<script type="application/ld+json">{"@type": "Product",</script>`)
+
+		Expect(data.Issues).To(Equal([]*model.StructuredDataIssue{
+			{
+				Code:     model.StructuredInvalidJSONLD,
+				Severity: model.SeverityMedium,
+				Message:  "the JSON-LD block at html > body > script:nth-child(2) can't be parsed: unexpected end of JSON input",
+			},
+			{
+				Code:     model.StructuredMissingRequired,
+				Severity: model.SeverityHigh,
+				Entity:   "_:b2",
+				Type:     "Offer",
+				Property: "price",
+				Message:  "Offer requires the price property",
+			},
+		}))
+	})
+
+	It("should merge entities declared with the same identifier", func() {
+		data := parse(`<script type="application/ld+json">{"@type": "Organization", "@id": "https://example.com/#org", "name": "Example"}</script>
+			<div itemscope itemtype="https://schema.org/Corporation" itemid="https://example.com/#org">
+				<link itemprop="logo" href="/logo.png">
+			</div>`)
+
+		Expect(data.Entities).To(HaveLen(1))
+		Expect(data.Entities[0].Types).To(Equal([]string{"Organization", "Corporation"}))
+		Expect(data.Entities[0].Properties["logo"]).To(Equal(value("https://shop.example.com/logo.png")))
+	})
+
+	It("should merge relative identifiers resolved against the document", func() {
+		data := parse(`<script type="application/ld+json">{"@type": "Organization", "@id": "#org", "name": "Example"}</script>
+			<div vocab="https://schema.org/" typeof="Corporation" resource="/products/1#org">
+				<span property="legalName">Example Ltd</span>
+			</div>`)
+
+		Expect(data.Entities).To(HaveLen(1))
+		Expect(data.Entities[0].ID).To(Equal("https://shop.example.com/products/1#org"))
+		Expect(data.Entities[0].Types).To(Equal([]string{"Organization", "Corporation"}))
+	})
+})